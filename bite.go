@@ -67,6 +67,29 @@ type Application struct {
 	DisableOutputFormatController bool
 	OutPut                        *string
 	PersistentFlags               func(*pflag.FlagSet)
+	// EnvFallback if true(default is false) then each flag that is not set by the command line
+	// falls back to an environment variable derived from the `EnvPrefix` and the flag name, i.e `MYCLI_OUTPUT`.
+	EnvFallback bool
+	// EnvPrefix is the prefix of the environment variables of the flags, defaults to the `Name`.
+	EnvPrefix string
+	// ConfigFile if true(default is false) then the user config file is loaded before each command's execution
+	// and its values are the defaults of the matching flags, see `ApplyConfig`.
+	// Its path can be changed through the `--config` flag or its environment variable, i.e `MYCLI_CONFIG`.
+	ConfigFile bool
+	// ConfigPath is the default path of the user config file, defaults to `~/.config/<Name>/config.yaml`.
+	ConfigPath string
+	// FileFlags if true(default is false) then every string and string slice flag accepts `@file`, or `@-` for the standard input,
+	// as its value and the flag's value is replaced by the contents, i.e `--query=@query.sql`, see `ApplyFileFlags`.
+	FileFlags bool
+	// PersistentMemory if true(default is false) then the persisted `Memory` values, see `Memory.Persist`,
+	// are kept on disk between invocations and the `cache clear` command is registered.
+	PersistentMemory bool
+	// CacheDir is the directory of the persistent memory, defaults to `os.UserCacheDir()/<Name>`.
+	CacheDir string
+	// OnInterrupt if not nil then the `Memory` is closed when the process is interrupted (SIGINT or SIGTERM) while a command runs,
+	// and the OnInterrupt is called with the signal and the close error, if any. It decides how the application exits, i.e `os.Exit(130)`.
+	// Otherwise the signals terminate the process as usual.
+	OnInterrupt func(sig os.Signal, closeErr error)
 
 	Setup          CobraRunner
 	Shutdown       CobraRunner
@@ -127,26 +150,33 @@ func PrintObject(cmd *cobra.Command, v interface{}, tableOnlyFilters ...interfac
 	} else if strings.ToUpper(outputFlagValue) == "YAML" {
 		return WriteYAML(out, v)
 	} else {
-		app := Get(cmd)
-		// normally the io.Writer is one, so the tableprinter; the app(it's io.Writer: cmd -> root command's output -> run(w io.Writer) -> app.Write)
-		// but it can be changed manually before this call, so make a check to have only one talbeprinter instance per those writers.
-		app.tablePrintersMu.RLock()
-		printer, ok := app.tablePrintersCache[out]
-		app.tablePrintersMu.RUnlock()
-		if !ok {
-			// register it.
+		var printer *tableprinter.Printer
+
+		if app := Get(cmd); app == nil {
+			// the command is not part of an application, i.e a test one, so there is no printer to reuse and no header colors.
 			printer = tableprinter.New(out)
-			app.tablePrintersMu.Lock()
-			app.tablePrintersCache[out] = printer
-			app.tablePrintersMu.Unlock()
-		}
+		} else {
+			// normally the io.Writer is one, so the tableprinter; the app(it's io.Writer: cmd -> root command's output -> run(w io.Writer) -> app.Write)
+			// but it can be changed manually before this call, so make a check to have only one talbeprinter instance per those writers.
+			app.tablePrintersMu.RLock()
+			cached, ok := app.tablePrintersCache[out]
+			app.tablePrintersMu.RUnlock()
+			if !ok {
+				// register it.
+				cached = tableprinter.New(out)
+				app.tablePrintersMu.Lock()
+				app.tablePrintersCache[out] = cached
+				app.tablePrintersMu.Unlock()
+			}
+			printer = cached
 
-		if v := app.TableHeaderFgColor; v != "" {
-			printer.HeaderFgColor = whichColor(v, 30)
-		}
+			if v := app.TableHeaderFgColor; v != "" {
+				printer.HeaderFgColor = whichColor(v, 30)
+			}
 
-		if v := app.TableHeaderBgColor; v != "" {
-			printer.HeaderBgColor = whichColor(v, 40)
+			if v := app.TableHeaderBgColor; v != "" {
+				printer.HeaderBgColor = whichColor(v, 40)
+			}
 		}
 
 		// This will try to append a struct-only as a row
@@ -197,6 +227,10 @@ func (app *Application) AddCommand(cmd *cobra.Command) {
 	} else {
 		// builded, add them directly as cobra commands.
 		app.CobraCommand.AddCommand(cmd)
+
+		if app.EnvFallback {
+			annotateEnvUsage(cmd, app.envPrefix())
+		}
 	}
 }
 
@@ -233,19 +267,26 @@ func (app *Application) Run(output io.Writer, args []string) error {
 		args = os.Args[1:]
 	}
 
+	if rewritten, ok := RewriteDeprecatedCommands(rootCmd, args); ok {
+		args = rewritten
+		rootCmd.SetArgs(args)
+	}
+
 	if !rootCmd.DisableFlagParsing {
 		rootCmd.ParseFlags(args)
 	}
 
 	app.commands = nil
 
+	defer app.closeMemoryOnInterrupt()()
+
 	if app.ShowSpinner {
-		return ackError(app.FriendlyErrors, ExecuteWithSpinner(rootCmd))
+		return ackError(app.FriendlyErrors, app.closeMemory(rootCmd, ExecuteWithSpinner(rootCmd)))
 	}
 
 	app.AddCommand(newBashCompletionCommand(rootCmd))
 
-	return ackError(app.FriendlyErrors, rootCmd.Execute())
+	return ackError(app.FriendlyErrors, app.closeMemory(rootCmd, rootCmd.Execute()))
 }
 
 func (app *Application) exampleText(str string) string {
@@ -346,6 +387,21 @@ func Build(app *Application) *cobra.Command {
 		app.Memory = makeMemory()
 	}
 
+	if app.PersistentMemory && app.Memory.Store == nil {
+		cacheDir := app.CacheDir
+		if cacheDir == "" {
+			var err error
+			if cacheDir, err = DefaultCacheDir(strings.Split(app.Name, " ")[0]); err != nil {
+				// fallback to in-memory values, the persisted keys fail with that error, see `Persist`.
+				app.Memory.storeErr = fmt.Errorf("persistent memory: %v, set the application's CacheDir", err)
+			}
+		}
+
+		if app.Memory.storeErr == nil {
+			app.Memory.Store = NewDiskStore(cacheDir)
+		}
+	}
+
 	app.tablePrintersCache = make(map[io.Writer]*tableprinter.Printer)
 
 	useText := app.Name
@@ -372,7 +428,6 @@ func Build(app *Application) *cobra.Command {
 
 	app.OutPut = new(string)
 
-	fmt.Sprintf("HEEEEEERREEEEE flag is [%v] ", app.DisableOutputFormatController)
 	if !app.DisableOutputFormatController {
 		RegisterOutPutFlagTo(fs, app.OutPut)
 
@@ -380,6 +435,15 @@ func Build(app *Application) *cobra.Command {
 		fs.StringVar(&app.TableHeaderBgColor, "header-bgcolor", "", "Table headers background gcolor=white")
 	}
 
+	if app.ConfigFile {
+		configPath := app.ConfigPath
+		if configPath == "" {
+			configPath = DefaultConfigPath(strings.Split(app.Name, " ")[0])
+		}
+
+		RegisterConfigFlagTo(fs, configPath)
+	}
+
 	if app.PersistentFlags != nil {
 		app.PersistentFlags(fs)
 	}
@@ -387,6 +451,24 @@ func Build(app *Application) *cobra.Command {
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
 		app.currentCommand = cmd // bind current command here.
 
+		if app.EnvFallback {
+			if err := applyEnvFlags(cmd, app.envPrefix()); err != nil {
+				return err
+			}
+		}
+
+		if app.ConfigFile {
+			if err := app.loadConfig(cmd); err != nil {
+				return err
+			}
+		}
+
+		if app.FileFlags {
+			if err := ApplyFileFlags(cmd); err != nil {
+				return err
+			}
+		}
+
 		if app.Setup != nil {
 			return app.Setup(cmd, args)
 		}
@@ -395,11 +477,19 @@ func Build(app *Application) *cobra.Command {
 	}
 
 	rootCmd.PersistentPostRunE = func(cmd *cobra.Command, args []string) error {
+		var err error
 		if app.Shutdown != nil {
-			return app.Shutdown(cmd, args)
+			err = app.Shutdown(cmd, args)
 		}
 
-		return nil
+		// even if the shutdown failed, the shutdown's error takes precedence.
+		if app.PersistentMemory {
+			if flushErr := app.Memory.Flush(); err == nil {
+				err = flushErr
+			}
+		}
+
+		return err
 	}
 
 	if len(app.commands) > 0 {
@@ -422,6 +512,11 @@ func Build(app *Application) *cobra.Command {
 		}
 	}
 
+	// registered after the root's example is picked from the first command, "cache clear" is not a useful example.
+	if app.PersistentMemory && app.GetCommand("cache") == nil {
+		rootCmd.AddCommand(newCacheCommand(app))
+	}
+
 	if versionName := "version"; app.GetCommand(versionName) == nil {
 		rootCmd.AddCommand(&cobra.Command{
 			Use:           versionName,
@@ -441,6 +536,10 @@ func Build(app *Application) *cobra.Command {
 		})
 	}
 
+	if app.EnvFallback {
+		annotateEnvUsage(rootCmd, app.envPrefix())
+	}
+
 	app.currentCommand = rootCmd
 	app.CobraCommand = rootCmd
 
@@ -500,6 +599,26 @@ func (b *ApplicationBuilder) Setup(setupFunc CobraRunner) *ApplicationBuilder {
 	return b
 }
 
+// EnvFallback makes each flag to fall back to an environment variable, see `Application.EnvFallback`,
+// an empty "prefix" means the application name.
+func (b *ApplicationBuilder) EnvFallback(prefix string) *ApplicationBuilder {
+	b.app.EnvFallback = true
+	b.app.EnvPrefix = prefix
+	return b
+}
+
+// PersistentMemory makes the persisted memory values to be kept on disk, see `Application.PersistentMemory`.
+func (b *ApplicationBuilder) PersistentMemory() *ApplicationBuilder {
+	b.app.PersistentMemory = true
+	return b
+}
+
+// FileFlags makes every string and string slice flag to accept `@file` values, see `Application.FileFlags`.
+func (b *ApplicationBuilder) FileFlags() *ApplicationBuilder {
+	b.app.FileFlags = true
+	return b
+}
+
 func (b *ApplicationBuilder) Flags(fn func(*Flags)) *ApplicationBuilder {
 	b.app.PersistentFlags = fn
 	return b
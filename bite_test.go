@@ -2,6 +2,10 @@ package bite
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
@@ -59,3 +63,42 @@ func TestGetNested(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// writeTestFiles writes the "files", by their relative path, to a temporary directory of the test and returns its path.
+func writeTestFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+// executeTestApp builds the "app" and executes it with the "args", its output is discarded.
+// Applications are registered by name, so if the "app" has no name then it's named after the (sub)test.
+func executeTestApp(t *testing.T, app *Application, args ...string) error {
+	t.Helper()
+
+	if app.Name == "" {
+		app.Name = strings.Map(func(r rune) rune {
+			if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+				return r
+			}
+			return '-'
+		}, t.Name())
+	}
+
+	rootCmd := Build(app)
+	rootCmd.SetOutput(ioutil.Discard)
+	rootCmd.SetArgs(args)
+	return rootCmd.Execute()
+}
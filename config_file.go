@@ -0,0 +1,164 @@
+package bite
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// configFlagKey is the persistent `--config` flag, the path of the user config file, see `Application.ConfigFile`.
+	configFlagKey = "config"
+	// configCommandsKey is the config file's key of the per-command flag values, i.e `commands.topics.list.limit: 50`.
+	configCommandsKey = "commands"
+
+	flagSourceConfig = "config"
+)
+
+// DefaultConfigPath returns the default path of the user config file of an application, i.e `~/.config/mycli/config.yaml`.
+func DefaultConfigPath(applicationName string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".config", applicationName, "config.yaml")
+}
+
+// configPath returns the path of the user config file and if it's explicitly set by the user,
+// the `--config` flag takes precedence over the environment variable, i.e `MYCLI_CONFIG`, and the `ConfigPath`.
+// Only the root's persistent `--config` flag is used, a command may have a local `--config` flag of its own.
+func (app *Application) configPath(cmd *cobra.Command) (string, bool) {
+	if f := cmd.Root().PersistentFlags().Lookup(configFlagKey); f != nil && f.Changed {
+		return f.Value.String(), true
+	}
+
+	if path := os.Getenv(EnvName(app.envPrefix(), configFlagKey)); path != "" {
+		return path, true
+	}
+
+	if app.ConfigPath != "" {
+		return app.ConfigPath, false
+	}
+
+	return DefaultConfigPath(strings.Split(app.Name, " ")[0]), false
+}
+
+// loadConfig reads the user config file and applies its values as defaults to the "cmd"'s flags.
+// A missing config file is not an error, unless its path is explicitly set.
+func (app *Application) loadConfig(cmd *cobra.Command) error {
+	path, explicit := app.configPath(cmd)
+	if path == "" {
+		return nil
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return nil
+		}
+
+		return err
+	}
+
+	var doc interface{}
+	if _, _, err := loadDocument(cmd, path, &doc, nil); err != nil {
+		return err
+	}
+
+	config, ok := normalizeDocument(doc).(map[string]interface{})
+	if !ok {
+		if doc == nil { // empty file.
+			return nil
+		}
+
+		return fmt.Errorf("config file '%s' is not an object", path)
+	}
+
+	return ApplyConfig(cmd, config)
+}
+
+// ApplyConfig sets the "config" values to the "cmd"'s flags that are not set by the command line (or by environment variables).
+// Top-level keys match the flag names, i.e `output: json`, and the values under the `commands` key
+// match the flags of a specific command by its path, i.e `commands: {topics: {list: {limit: 50}}}`, the more specific the higher the priority.
+//
+// Lists are set as comma-separated values and objects as comma-separated key=value pairs.
+func ApplyConfig(cmd *cobra.Command, config map[string]interface{}) error {
+	set := cmd.Flags()
+	values := make(map[string]interface{})
+
+	collect := func(node map[string]interface{}) {
+		for key, value := range node {
+			if key != configCommandsKey && set.Lookup(key) != nil {
+				values[key] = value
+			}
+		}
+	}
+
+	collect(config)
+
+	node, _ := config[configCommandsKey].(map[string]interface{})
+	// skip the root command's name, i.e "mycli topics list" to "topics", "list".
+	for _, name := range strings.Fields(cmd.CommandPath())[1:] {
+		if node == nil {
+			break
+		}
+
+		node, _ = node[name].(map[string]interface{})
+		collect(node)
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		f := set.Lookup(name)
+		if source := flagSource(f); source == flagSourceFlag || source == flagSourceEnv {
+			continue
+		}
+
+		// not through `set.Set` as they are defaults and not explicitly set by the user.
+		if err := f.Value.Set(configValueString(values[name])); err != nil {
+			return fmt.Errorf("invalid config value of flag %s: %v", name, err)
+		}
+
+		setFlagSource(f, flagSourceConfig)
+	}
+
+	return nil
+}
+
+func configValueString(v interface{}) string {
+	switch vv := v.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		parts := make([]string, len(vv))
+		for i, item := range vv {
+			parts[i] = configValueString(item)
+		}
+		return strings.Join(parts, ",")
+	case map[string]interface{}:
+		pairs := make([]string, 0, len(vv))
+		for key, value := range vv {
+			pairs = append(pairs, key+"="+configValueString(value))
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ",")
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// RegisterConfigFlagTo registers the `--config` flag to the "set".
+func RegisterConfigFlagTo(set *pflag.FlagSet, defaultPath string) {
+	set.String(configFlagKey, defaultPath, "Path of the config file that keeps the default flag values")
+	cobra.MarkFlagFilename(set, configFlagKey)
+}
@@ -0,0 +1,125 @@
+package bite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestApplicationConfigFile(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"config.yaml": `
+limit: 10
+commands:
+  topics:
+    limit: 20
+    list:
+      limit: 50
+`,
+		"other.yaml": "limit: 5\n",
+	})
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedLimit int
+		expectedLocal string
+	}{
+		{"nested command", []string{"topics", "list"}, 50, ""},
+		{"parent command", []string{"topics", "describe"}, 20, ""},
+		{"explicit path", []string{"--config=" + filepath.Join(dir, "other.yaml"), "topics", "describe"}, 5, ""},
+		// the local --config flag of the command is not the config file.
+		{"local config flag", []string{"topics", "list", "--config=local"}, 50, "local"},
+		{"flag over config", []string{"topics", "list", "--limit=1"}, 1, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				limit int
+				local string
+			)
+
+			listCmd := &cobra.Command{Use: "list", RunE: func(*cobra.Command, []string) error { return nil }}
+			listCmd.Flags().IntVar(&limit, "limit", 0, "")
+			listCmd.Flags().StringVar(&local, "config", "", "")
+
+			describeCmd := &cobra.Command{Use: "describe", RunE: func(*cobra.Command, []string) error { return nil }}
+			describeCmd.Flags().IntVar(&limit, "limit", 0, "")
+
+			topicsCmd := &cobra.Command{Use: "topics"}
+			topicsCmd.AddCommand(listCmd, describeCmd)
+
+			app := &Application{ConfigFile: true, ConfigPath: filepath.Join(dir, "config.yaml")}
+			app.AddCommand(topicsCmd)
+
+			if err := executeTestApp(t, app, tt.args...); err != nil {
+				t.Fatal(err)
+			}
+
+			if limit != tt.expectedLimit {
+				t.Fatalf("expected limit %d but got %d", tt.expectedLimit, limit)
+			}
+
+			if local != tt.expectedLocal {
+				t.Fatalf("expected local config flag %q but got %q", tt.expectedLocal, local)
+			}
+		})
+	}
+}
+
+func TestApplicationConfigSources(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{"config.yaml": "limit: 50\nfilter: config\n"})
+
+	tests := []struct {
+		name           string
+		env            map[string]string
+		args           []string
+		expectedLimit  int
+		expectedFilter string
+		expectedSource string
+	}{
+		{"config", nil, nil, 50, "config", flagSourceConfig},
+		{"env over config", map[string]string{"BITE_SOURCES_LIMIT": "7"}, nil, 7, "config", flagSourceEnv},
+		{"flag over env and config", map[string]string{"BITE_SOURCES_LIMIT": "7"}, []string{"--limit=1"}, 1, "config", flagSourceFlag},
+		{"flag over config", nil, []string{"--limit=1", "--filter=flag"}, 1, "flag", flagSourceFlag},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			var (
+				limit  int
+				filter string
+			)
+
+			cmd := &cobra.Command{Use: "list", RunE: func(*cobra.Command, []string) error { return nil }}
+			cmd.Flags().IntVar(&limit, "limit", 0, "")
+			cmd.Flags().StringVar(&filter, "filter", "", "")
+
+			app := &Application{
+				EnvFallback: true,
+				EnvPrefix:   "bite_sources",
+				ConfigFile:  true,
+				ConfigPath:  filepath.Join(dir, "config.yaml"),
+			}
+			app.AddCommand(cmd)
+
+			if err := executeTestApp(t, app, append([]string{"list"}, tt.args...)...); err != nil {
+				t.Fatal(err)
+			}
+
+			if limit != tt.expectedLimit || filter != tt.expectedFilter {
+				t.Fatalf("expected limit %d and filter %q but got %d and %q", tt.expectedLimit, tt.expectedFilter, limit, filter)
+			}
+
+			if source := flagSource(cmd.Flags().Lookup("limit")); source != tt.expectedSource {
+				t.Fatalf("expected the limit to be set by %s but got %s", tt.expectedSource, source)
+			}
+		})
+	}
+}
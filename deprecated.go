@@ -0,0 +1,211 @@
+package bite
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// deprecatedPathsAnnotation keeps the old paths of a command, relative to the root command, separated by ';'.
+	deprecatedPathsAnnotation = "bite_deprecated_paths"
+	// deprecationWarningsAnnotation is set when the command prints the pending deprecation warnings before its `RunE`.
+	deprecationWarningsAnnotation = "bite_deprecation_warnings"
+)
+
+var (
+	deprecationWarnings        []string
+	printedDeprecationWarnings = make(map[string]bool)
+	deprecationWarningsMu      sync.Mutex
+)
+
+func deprecationMessage(kind, oldName, newName, removalVersion string) string {
+	removal := "in a future release"
+	if removalVersion != "" {
+		removal = "in " + removalVersion
+	}
+
+	// Flag --partition has been deprecated and will be removed in v3.0.0, use --partitions instead
+	return fmt.Sprintf("%s %s has been deprecated and will be removed %s, use %s instead", kind, oldName, removal, newName)
+}
+
+func addDeprecationWarning(warning string) {
+	deprecationWarningsMu.Lock()
+	if !printedDeprecationWarnings[warning] {
+		printedDeprecationWarnings[warning] = true
+		deprecationWarnings = append(deprecationWarnings, warning)
+	}
+	deprecationWarningsMu.Unlock()
+}
+
+// printDeprecationWarnings prints the pending deprecation warnings to the standard error, once,
+// unless the `--silent` flag is set.
+func printDeprecationWarnings(cmd *cobra.Command) error {
+	deprecationWarningsMu.Lock()
+	warnings := deprecationWarnings
+	deprecationWarnings = nil
+	deprecationWarningsMu.Unlock()
+
+	if GetSilentFlag(cmd) {
+		return nil
+	}
+
+	for _, warning := range warnings {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s\n", warning)
+	}
+
+	return nil
+}
+
+func warnDeprecationsBeforeRun(cmd *cobra.Command) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = make(map[string]string)
+	}
+
+	if _, registered := cmd.Annotations[deprecationWarningsAnnotation]; registered {
+		return
+	}
+
+	cmd.Annotations[deprecationWarningsAnnotation] = "true"
+	prependPreRunE(cmd, printDeprecationWarnings)
+}
+
+// prependPreRunE makes the "fn" to run before the "cmd"'s current `PreRunE` or `PreRun`, if any.
+// Cobra ignores the `PreRun` when a `PreRunE` is set, so it's called by the new `PreRunE` instead.
+func prependPreRunE(cmd *cobra.Command, fn func(*cobra.Command) error) {
+	preRunE, preRun := cmd.PreRunE, cmd.PreRun
+	cmd.PreRunE = func(c *cobra.Command, args []string) error {
+		if err := fn(c); err != nil {
+			return err
+		}
+
+		if preRunE != nil {
+			return preRunE(c, args)
+		}
+
+		if preRun != nil {
+			preRun(c, args)
+		}
+
+		return nil
+	}
+}
+
+type deprecatedFlagValue struct {
+	pflag.Value
+	set     *pflag.FlagSet
+	name    string
+	warning string
+}
+
+func (v *deprecatedFlagValue) Set(s string) error {
+	addDeprecationWarning(v.warning)
+	return v.set.Set(v.name, s)
+}
+
+// DeprecatedFlag registers the "oldName" as a hidden alias of the "newName" flag of the "cmd" command,
+// the alias still works but a deprecation warning, which names the replacement and the "removalVersion", is printed once.
+// The "newName" flag should be registered first.
+func DeprecatedFlag(cmd *cobra.Command, oldName, newName, removalVersion string) {
+	set := cmd.Flags()
+	f := set.Lookup(newName)
+	if f == nil {
+		panic(fmt.Sprintf("deprecated flag %s: flag %s is not registered", oldName, newName))
+	}
+
+	warning := deprecationMessage("Flag", "--"+oldName, "--"+newName, removalVersion)
+	set.AddFlag(&pflag.Flag{
+		Name:        oldName,
+		Usage:       warning,
+		Value:       &deprecatedFlagValue{Value: f.Value, set: set, name: newName, warning: warning},
+		DefValue:    f.DefValue,
+		NoOptDefVal: f.NoOptDefVal,
+		Hidden:      true,
+	})
+
+	warnDeprecationsBeforeRun(cmd)
+}
+
+// DeprecatedCommandPath registers the "oldPath", relative to the root command, i.e "topics create", as an alias of the "cmd" command,
+// the alias still works but a deprecation warning, which names the replacement and the "removalVersion", is printed once.
+// The old paths are resolved by the `Application.Run` or manually through `RewriteDeprecatedCommands`.
+func DeprecatedCommandPath(cmd *cobra.Command, oldPath, removalVersion string) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = make(map[string]string)
+	}
+
+	deprecation := strings.Join(strings.Fields(oldPath), " ") + ":" + removalVersion
+	if paths := cmd.Annotations[deprecatedPathsAnnotation]; paths != "" {
+		deprecation = paths + ";" + deprecation
+	}
+	cmd.Annotations[deprecatedPathsAnnotation] = deprecation
+
+	warnDeprecationsBeforeRun(cmd)
+}
+
+// RewriteDeprecatedCommands replaces the leading "args" that match an old path of a command, see `DeprecatedCommandPath`,
+// with the command's current path and reports whether the "args" were rewritten, the "root" should be the root command.
+func RewriteDeprecatedCommands(root *cobra.Command, args []string) ([]string, bool) {
+	var (
+		rewritten []string
+		found     bool
+	)
+
+	visitCommands(root, func(cmd *cobra.Command) {
+		if found {
+			return
+		}
+
+		for _, deprecation := range strings.Split(cmd.Annotations[deprecatedPathsAnnotation], ";") {
+			idx := strings.LastIndexByte(deprecation, ':')
+			if idx <= 0 {
+				continue
+			}
+
+			oldPath, removalVersion := strings.Fields(deprecation[:idx]), deprecation[idx+1:]
+			if !hasPrefixArgs(args, oldPath) {
+				continue
+			}
+
+			// skip the root command's name.
+			newPath := strings.Fields(cmd.CommandPath())[1:]
+			rewritten = append(newPath, args[len(oldPath):]...)
+			found = true
+
+			addDeprecationWarning(deprecationMessage("Command",
+				strconv.Quote(strings.Join(oldPath, " ")), strconv.Quote(strings.Join(newPath, " ")), removalVersion))
+			return
+		}
+	})
+
+	if !found {
+		return args, false
+	}
+
+	return rewritten, true
+}
+
+func visitCommands(cmd *cobra.Command, visitor func(*cobra.Command)) {
+	visitor(cmd)
+	for _, c := range cmd.Commands() {
+		visitCommands(c, visitor)
+	}
+}
+
+func hasPrefixArgs(args, prefix []string) bool {
+	if len(prefix) == 0 || len(args) < len(prefix) {
+		return false
+	}
+
+	for i := range prefix {
+		if args[i] != prefix[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,87 @@
+package bite
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestDeprecatedFlag(t *testing.T) {
+	var partitions int
+	cmd := &cobra.Command{Use: "create", RunE: func(*cobra.Command, []string) error { return nil }}
+	cmd.Flags().IntVar(&partitions, "partitions", 1, "")
+	DeprecatedFlag(cmd, "partition", "partitions", "v3.0.0")
+
+	stderr := new(bytes.Buffer)
+	cmd.SetErr(stderr)
+
+	for i := 0; i < 2; i++ {
+		cmd.SetArgs([]string{"--partition=3"})
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if partitions != 3 || !cmd.Flags().Changed("partitions") {
+		t.Fatalf("expected the alias to set the new flag but got: %d", partitions)
+	}
+
+	expected := "Warning: Flag --partition has been deprecated and will be removed in v3.0.0, use --partitions instead\n"
+	if got := stderr.String(); got != expected {
+		t.Fatalf("expected a one-time warning:\n%s\nbut got:\n%s", expected, got)
+	}
+}
+
+func TestRewriteDeprecatedCommands(t *testing.T) {
+	root := &cobra.Command{Use: "mycli"}
+	topic := &cobra.Command{Use: "topic"}
+	create := &cobra.Command{Use: "create"}
+	topic.AddCommand(create)
+	root.AddCommand(topic)
+	DeprecatedCommandPath(create, "topics create", "")
+
+	args, ok := RewriteDeprecatedCommands(root, []string{"topics", "create", "--name=a"})
+	if expected := []string{"topic", "create", "--name=a"}; !ok || !reflect.DeepEqual(args, expected) {
+		t.Fatalf("expected args %v but got %v", expected, args)
+	}
+
+	if _, ok = RewriteDeprecatedCommands(root, []string{"topic", "create"}); ok {
+		t.Fatal("expected current path to not be rewritten")
+	}
+
+	stderr := new(bytes.Buffer)
+	create.SetErr(stderr)
+	printDeprecationWarnings(create)
+	if !strings.Contains(stderr.String(), `Command "topics create" has been deprecated and will be removed in a future release, use "topic create" instead`) {
+		t.Fatalf("unexpected warning: %s", stderr.String())
+	}
+}
+
+func TestPrependPreRunE(t *testing.T) {
+	var calls []string
+	cmd := &cobra.Command{
+		Use:    "create",
+		PreRun: func(*cobra.Command, []string) { calls = append(calls, "pre run") },
+		RunE:   func(*cobra.Command, []string) error { return nil },
+	}
+
+	for _, name := range []string{"first", "second"} {
+		name := name
+		prependPreRunE(cmd, func(*cobra.Command) error {
+			calls = append(calls, name)
+			return nil
+		})
+	}
+
+	cmd.SetArgs(nil)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"second", "first", "pre run"}; !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("expected calls %v but got %v", expected, calls)
+	}
+}
@@ -0,0 +1,151 @@
+package bite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// FileDecodeError is the error that `LoadFile` returns when a file's contents can not be decoded,
+// it keeps the location of the failure and a snippet of the source with a caret under it.
+//
+// Its `Error` is a human readable message, unless the command's `--output` is JSON or YAML,
+// in that case the error is rendered as a structured object.
+type FileDecodeError struct {
+	Path    string `json:"path" yaml:"path"`
+	Format  string `json:"format" yaml:"format"`
+	Line    int    `json:"line,omitempty" yaml:"line,omitempty"`
+	Column  int    `json:"column,omitempty" yaml:"column,omitempty"`
+	Snippet string `json:"snippet,omitempty" yaml:"snippet,omitempty"`
+	Message string `json:"message" yaml:"message"`
+
+	Err    error  `json:"-" yaml:"-"` // the decoder's error.
+	output string // the --output flag value of the command.
+}
+
+func (e *FileDecodeError) Error() string {
+	switch strings.ToUpper(e.output) {
+	case "JSON":
+		if b, err := MarshalJSON(e, false); err == nil {
+			return string(b)
+		}
+	case "YAML":
+		if b, err := yaml.Marshal(e); err == nil {
+			return strings.TrimSpace(string(b))
+		}
+	}
+
+	location := fmt.Sprintf("'%s'", e.Path)
+	if e.Line > 0 {
+		location += fmt.Sprintf(" at line %d", e.Line)
+		if e.Column > 0 {
+			location += fmt.Sprintf(", column %d", e.Column)
+		}
+	}
+
+	msg := fmt.Sprintf("failed to decode %s: %s", location, e.Message)
+	if e.Snippet != "" {
+		msg += "\n" + e.Snippet
+	}
+
+	return msg
+}
+
+// Unwrap returns the decoder's error.
+func (e *FileDecodeError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	decodeErrLineExpr   = regexp.MustCompile(`(?i)line (\d+)`)
+	decodeErrPrefixExpr = regexp.MustCompile(`^(json: |yaml: |toml: )?((?i:near )?line \d+[^:]*: )?`)
+)
+
+func newFileDecodeError(cmd *cobra.Command, path, format string, data []byte, err error) *FileDecodeError {
+	e := &FileDecodeError{
+		Path:    path,
+		Format:  format,
+		Message: err.Error(),
+		Err:     err,
+		output:  GetOutPutFlag(cmd),
+	}
+
+	switch decodeErr := err.(type) {
+	case *json.SyntaxError:
+		e.Line, e.Column = offsetPosition(data, decodeErr.Offset)
+	case *json.UnmarshalTypeError:
+		e.Line, e.Column = offsetPosition(data, decodeErr.Offset)
+	case *yaml.TypeError:
+		if len(decodeErr.Errors) > 0 {
+			e.Message = decodeErr.Errors[0]
+		}
+	}
+
+	if e.Line == 0 {
+		// yaml and toml errors keep the line in their messages, i.e "yaml: line 3: mapping values are not allowed in this context".
+		if matches := decodeErrLineExpr.FindStringSubmatch(e.Message); len(matches) > 1 {
+			e.Line, _ = strconv.Atoi(matches[1])
+		}
+	}
+
+	e.Message = decodeErrPrefixExpr.ReplaceAllString(e.Message, "")
+
+	if e.Line > 0 {
+		e.Snippet = sourceSnippet(data, e.Line, &e.Column)
+	}
+
+	return e
+}
+
+// offsetPosition converts a byte offset of the "data" to a line and column, both are 1-based.
+func offsetPosition(data []byte, offset int64) (line, column int) {
+	if offset < 0 {
+		offset = 0
+	} else if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+
+	before := data[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	column = len(before) - bytes.LastIndexByte(before, '\n') - 1
+	if column < 1 {
+		column = 1
+	}
+
+	return
+}
+
+// sourceSnippet returns the "line" of the "data" prefixed by its number and a caret under the "column",
+// if column is zero then the caret points to the first non-space character of the line and the column is set to that.
+func sourceSnippet(data []byte, line int, column *int) string {
+	lines := strings.Split(string(data), "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+
+	src := strings.TrimRight(lines[line-1], "\r")
+	if *column <= 0 {
+		*column = len(src) - len(strings.TrimLeft(src, " \t")) + 1
+	}
+
+	if *column > len(src)+1 {
+		*column = len(src) + 1
+	}
+
+	gutter := strconv.Itoa(line)
+	// keep tabs of the source line so the caret is aligned.
+	padding := strings.Map(func(r rune) rune {
+		if r == '\t' {
+			return r
+		}
+		return ' '
+	}, src[:*column-1])
+
+	return fmt.Sprintf("%s | %s\n%s | %s^", gutter, src, strings.Repeat(" ", len(gutter)), padding)
+}
@@ -0,0 +1,130 @@
+package bite
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestOffsetPosition(t *testing.T) {
+	data := []byte("{\n  \"name\": \"a\",\n  \"partitions\": x\n}")
+
+	tests := []struct {
+		name           string
+		offset         int64
+		expectedLine   int
+		expectedColumn int
+	}{
+		{"start of file", 0, 1, 1},
+		{"first byte", 1, 1, 1},
+		{"start of line", 2, 2, 1},
+		{"middle of line", 10, 2, 8},
+		{"end of line", 16, 2, 14},
+		{"after a new line", 17, 3, 1},
+		{"end of file", int64(len(data)), 4, 1},
+		{"after end of file", int64(len(data)) + 10, 4, 1},
+		{"negative", -1, 1, 1},
+	}
+
+	for _, tt := range tests {
+		line, column := offsetPosition(data, tt.offset)
+		if line != tt.expectedLine || column != tt.expectedColumn {
+			t.Fatalf("[%s] expected line %d and column %d but got %d and %d", tt.name, tt.expectedLine, tt.expectedColumn, line, column)
+		}
+	}
+
+	if line, column := offsetPosition(nil, 0); line != 1 || column != 1 {
+		t.Fatalf("expected line 1 and column 1 of empty data but got %d and %d", line, column)
+	}
+}
+
+func TestSourceSnippet(t *testing.T) {
+	data := []byte("name: a\r\n  partitions: abc\n\tconfig: x\nlast")
+
+	tests := []struct {
+		name           string
+		line           int
+		column         int
+		expected       string
+		expectedColumn int
+	}{
+		{"column", 1, 7, "1 | name: a\n  |       ^", 7},
+		{"first non-space", 2, 0, "2 |   partitions: abc\n  |   ^", 3},
+		{"tabs are kept", 3, 0, "3 | \tconfig: x\n  | \t^", 2},
+		{"column after end of line", 4, 10, "4 | last\n  |     ^", 5},
+		{"line after end of file", 5, 1, "", 1},
+		{"zero line", 0, 1, "", 1},
+	}
+
+	for _, tt := range tests {
+		column := tt.column
+		if got := sourceSnippet(data, tt.line, &column); got != tt.expected {
+			t.Fatalf("[%s] expected snippet:\n%s\nbut got:\n%s", tt.name, tt.expected, got)
+		}
+
+		if column != tt.expectedColumn {
+			t.Fatalf("[%s] expected column %d but got %d", tt.name, tt.expectedColumn, column)
+		}
+	}
+}
+
+func TestFileDecodeError(t *testing.T) {
+	var outPtr struct {
+		Name       string `json:"name" yaml:"name" toml:"name"`
+		Partitions int    `json:"partitions" yaml:"partitions" toml:"partitions"`
+	}
+
+	tests := []struct {
+		name     string
+		format   string
+		data     string
+		output   string
+		expected string
+	}{
+		{
+			"json syntax", formatJSON, "{\n  \"name\": \"a\",\n  \"partitions\": x\n}", "table",
+			"failed to decode 'topic.json' at line 3, column 17: invalid character 'x' looking for beginning of value\n" +
+				"3 |   \"partitions\": x\n  |                 ^",
+		},
+		{
+			"json end of file", formatJSON, "{\n  \"name\": \"a\"", "table",
+			"failed to decode 'topic.json' at line 2, column 13: unexpected end of JSON input\n" +
+				"2 |   \"name\": \"a\"\n  |             ^",
+		},
+		{
+			"json type", formatJSON, "{\"partitions\": \"3\"}", "json",
+			`{"path":"topic.json","format":"json","line":1,"column":18,"snippet":"1 | {\"partitions\": \"3\"}\n  |                  ^",` +
+				`"message":"cannot unmarshal string into Go struct field .partitions of type int"}`,
+		},
+		{
+			"yaml type", formatYAML, "name: a\npartitions: abc\n", "yaml",
+			"path: topic.yaml\nformat: yaml\nline: 2\ncolumn: 1\nsnippet: |-\n  2 | partitions: abc\n    | ^\nmessage: cannot unmarshal !!str `abc` into int",
+		},
+		{
+			"toml", formatTOML, "name = \"a\"\npartitions = \n", "table",
+			"failed to decode 'topic.toml' at line 2, column 1: expected value but found '\\n' instead\n" +
+				"2 | partitions = \n  | ^",
+		},
+	}
+
+	for _, tt := range tests {
+		cmd := &cobra.Command{Use: "create"}
+		RegisterOutPutFlag(cmd, new(string))
+		cmd.Flags().Set(outputFlagKey, tt.output)
+
+		data := []byte(tt.data)
+		err := unmarshalFormat(tt.format, data, &outPtr)
+		if err == nil {
+			t.Fatalf("[%s] expected a decode error", tt.name)
+		}
+
+		decodeErr := newFileDecodeError(cmd, "topic."+tt.format, tt.format, data, err)
+		if got := decodeErr.Error(); got != tt.expected {
+			t.Fatalf("[%s] expected error:\n%s\nbut got:\n%s", tt.name, tt.expected, got)
+		}
+
+		if decodeErr.Unwrap() != err {
+			t.Fatalf("[%s] expected the decoder's error to be kept", tt.name)
+		}
+	}
+}
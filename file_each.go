@@ -0,0 +1,236 @@
+package bite
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// hasFileExtension reports whether a file should be collected from a directory, see `ResolveFiles`.
+func hasFileExtension(path string) bool {
+	return formatByExt(path) != ""
+}
+
+// ResolveFiles returns the files of a "path" which can be a single file, a directory or a glob pattern, i.e `./resources/*.yaml`.
+// Directories are scanned for json, yaml and toml files, compressed or not, if "recursive" is true then their sub directories are scanned too.
+// The result is sorted by name.
+func ResolveFiles(path string, recursive bool) ([]string, error) {
+	if strings.ContainsAny(path, "*?[") {
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var files []string
+		for _, match := range matches {
+			matchFiles, err := ResolveFiles(match, recursive)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, matchFiles...)
+		}
+
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no files match the pattern '%s'", path)
+		}
+
+		return files, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	err = filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if p != path && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if hasFileExtension(p) {
+			files = append(files, p)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// if true then directories are scanned recursively, see `FileBindEach`.
+// It's not a global flag, commands that load many files register it via `CanLoadRecursively`.
+const recursiveFlagKey = "recursive"
+
+// CanLoadRecursively registers the `--recursive, -R` flag to the "cmd" command.
+func CanLoadRecursively(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().BoolP(recursiveFlagKey, "R", false, "Process the directory used in the path argument recursively")
+	return cmd
+}
+
+// GetRecursiveFlag returns the value(true/false) of the `--recursive` flag,
+// however if not found it returns false too.
+func GetRecursiveFlag(cmd *cobra.Command) bool {
+	b, _ := cmd.Flags().GetBool(recursiveFlagKey)
+	return b
+}
+
+// RecursiveBind makes the `FileBindEach` to scan directories recursively, even if the `--recursive` flag is not set.
+func RecursiveBind() func(*FileLoader) {
+	return func(fl *FileLoader) {
+		fl.Recursive()
+	}
+}
+
+func (fl *FileLoader) Recursive() *FileLoader {
+	fl.recursive = true
+	return fl
+}
+
+// FileResult is a row of the summary table that `FileBindEach` prints at the end.
+type FileResult struct {
+	File   string `json:"file" yaml:"File" header:"File"`
+	Status string `json:"status" yaml:"Status" header:"Status"`
+	Error  string `json:"error,omitempty" yaml:"Error,omitempty" header:"Error"`
+}
+
+// FileBindEach is like `FileBind` but the resolved path can be a directory or a glob pattern, i.e `mycli apply ./resources/`.
+// Each matching file is decoded into a copy of the initial value of the "outPtr", i.e its defaults, and the "runner" is executed once per file,
+// failures do not stop the rest of the files. A summary table of successes and failures is printed at the end.
+func FileBindEach(outPtr interface{}, runner CobraRunner, customizers ...func(*FileLoader)) CobraRunner {
+	if outPtr == nil {
+		return emptyRunner
+	}
+
+	if reflect.TypeOf(outPtr).Kind() != reflect.Ptr {
+		panic("outPtr is not a pointer")
+	}
+
+	fl := newFileLoader()
+
+	for _, c := range customizers {
+		c(fl)
+	}
+
+	return func(cmd *cobra.Command, args []string) error {
+		path := fl.pathResolver(cmd, args)
+		if path == "" {
+			if fl.elseFunc != nil {
+				if err := fl.elseFunc(); err != nil {
+					return err
+				}
+			}
+
+			return runner(cmd, args)
+		}
+
+		files, err := ResolveFiles(path, fl.recursive || GetRecursiveFlag(cmd))
+		if err != nil {
+			return err
+		}
+
+		v := reflect.ValueOf(outPtr).Elem()
+		initial := cloneValue(v)
+		results := make([]FileResult, 0, len(files))
+		failed := 0
+
+		render, err := fl.renderer(cmd)
+		if err != nil {
+			return err
+		}
+
+		override := fl.overrideFlags(cmd, outPtr)
+
+		for _, file := range files {
+			v.Set(cloneValue(initial)) // fresh value for each file.
+			resetFileFlags(cmd)
+
+			err := loadFile(cmd, file, outPtr, render)
+			if err == nil {
+				if err = override(); err == nil {
+					err = runner(cmd, args)
+				}
+			}
+
+			result := FileResult{File: file, Status: "OK"}
+			if err != nil {
+				failed++
+				result.Status = "FAILED"
+				result.Error = err.Error()
+			}
+
+			results = append(results, result)
+		}
+
+		if err = PrintObject(cmd, results); err != nil {
+			return err
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d files failed", failed, len(files))
+		}
+
+		return nil
+	}
+}
+
+// cloneValue returns a deep copy of the "v", so decoding into the copy does not modify the maps, slices and pointers of the "v".
+func cloneValue(v reflect.Value) reflect.Value {
+	clone := reflect.New(v.Type()).Elem()
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			clone.Set(cloneValue(v.Elem()).Addr())
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			clone.Set(cloneValue(v.Elem()))
+		}
+	case reflect.Map:
+		if !v.IsNil() {
+			clone.Set(reflect.MakeMapWithSize(v.Type(), v.Len()))
+			for _, key := range v.MapKeys() {
+				clone.SetMapIndex(key, cloneValue(v.MapIndex(key)))
+			}
+		}
+	case reflect.Slice:
+		if !v.IsNil() {
+			clone.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+			for i := 0; i < v.Len(); i++ {
+				clone.Index(i).Set(cloneValue(v.Index(i)))
+			}
+		}
+	case reflect.Struct:
+		clone.Set(v) // unexported fields are kept as they are.
+		for i, n := 0, v.NumField(); i < n; i++ {
+			if field := clone.Field(i); field.CanSet() {
+				field.Set(cloneValue(v.Field(i)))
+			}
+		}
+	default:
+		clone.Set(v)
+	}
+
+	return clone
+}
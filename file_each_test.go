@@ -0,0 +1,111 @@
+package bite
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestResolveFiles(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"b.yaml":          "name: b",
+		"a.json":          `{"name": "a"}`,
+		"c.toml.gz":       "",
+		"readme.md":       "# resources",
+		"nested/d.yml":    "name: d",
+		"nested/e/f.json": `{"name": "f"}`,
+	})
+
+	join := func(names ...string) []string {
+		for i, name := range names {
+			names[i] = filepath.Join(dir, name)
+		}
+		return names
+	}
+
+	tests := []struct {
+		name      string
+		path      string
+		recursive bool
+		expected  []string
+	}{
+		{"file", filepath.Join(dir, "readme.md"), false, join("readme.md")},
+		{"dir", dir, false, join("a.json", "b.yaml", "c.toml.gz")},
+		{"recursive", dir, true, join("a.json", "b.yaml", "c.toml.gz", "nested/d.yml", "nested/e/f.json")},
+		{"glob", filepath.Join(dir, "*.json"), false, join("a.json")},
+		{"glob of dirs", filepath.Join(dir, "nest*"), true, join("nested/d.yml", "nested/e/f.json")},
+	}
+
+	for _, tt := range tests {
+		files, err := ResolveFiles(tt.path, tt.recursive)
+		if err != nil {
+			t.Fatalf("[%s] %v", tt.name, err)
+		}
+
+		if !reflect.DeepEqual(files, tt.expected) {
+			t.Fatalf("[%s] expected files %v but got %v", tt.name, tt.expected, files)
+		}
+	}
+
+	if _, err := ResolveFiles(filepath.Join(dir, "*.xml"), false); err == nil || !strings.Contains(err.Error(), "no files match") {
+		t.Fatalf("expected a no match error but got: %v", err)
+	}
+}
+
+func TestFileBindEach(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"a.yaml": "name: a\nconfig:\n  retention.ms: \"1000\"\n",
+		"b.yaml": "name: b\npartitions: 3\n",
+		"c.yaml": "name: [c]\n",
+	})
+
+	var topic struct {
+		Name       string            `yaml:"name"`
+		Partitions int               `yaml:"partitions"`
+		Config     map[string]string `yaml:"config"`
+	}
+	topic.Partitions = 1
+	topic.Config = map[string]string{"cleanup.policy": "delete"}
+
+	var loaded []string
+	cmd := CanLoadRecursively(&cobra.Command{Use: "apply"})
+	cmd.RunE = FileBindEach(&topic, func(*cobra.Command, []string) error {
+		loaded = append(loaded, fmt.Sprintf("%s:%d:%s", topic.Name, topic.Partitions, strings.Join(sortedKeys(topic.Config), ",")))
+		return nil
+	})
+
+	out := new(bytes.Buffer)
+	cmd.SetOutput(out)
+	cmd.SetArgs([]string{dir})
+
+	// the command is not part of an application, the summary is printed anyway.
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 files failed") {
+		t.Fatalf("expected one failure but got: %v", err)
+	}
+
+	// each file starts from the initial value, its defaults are kept and the previous files do not leak.
+	if expected := []string{"a:1:cleanup.policy,retention.ms", "b:3:cleanup.policy"}; !reflect.DeepEqual(loaded, expected) {
+		t.Fatalf("expected loaded %v but got %v", expected, loaded)
+	}
+
+	if summary := out.String(); !strings.Contains(summary, "FAILED") || !strings.Contains(summary, "c.yaml") {
+		t.Fatalf("expected the summary to contain the failed file but got:\n%s", summary)
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,204 @@
+package bite
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// Decompressor returns a reader of the decompressed contents of "r", see `RegisterDecompressor`.
+type Decompressor func(r io.Reader) (io.Reader, error)
+
+type decompression struct {
+	ext          string
+	magic        []byte
+	decompressor Decompressor
+}
+
+var (
+	decompressions   []decompression
+	decompressionsMu sync.RWMutex
+)
+
+// RegisterDecompressor registers a decompressor for files with the "ext" extension, i.e ".zst",
+// or contents that start with the "magic" bytes, i.e []byte{0x28, 0xb5, 0x2f, 0xfd}.
+// Files are decompressed transparently by `TryReadFileContents`, and so `TryReadFile` and `LoadFile`.
+//
+// Gzip is registered by default, other algorithms can be registered by the application, i.e:
+//
+//	bite.RegisterDecompressor(".zst", []byte{0x28, 0xb5, 0x2f, 0xfd}, func(r io.Reader) (io.Reader, error) {
+//		return zstd.NewReader(r)
+//	})
+func RegisterDecompressor(ext string, magic []byte, decompressor Decompressor) {
+	decompressionsMu.Lock()
+	decompressions = append(decompressions, decompression{ext: strings.ToLower(ext), magic: magic, decompressor: decompressor})
+	decompressionsMu.Unlock()
+}
+
+func init() {
+	RegisterDecompressor(".gz", []byte{0x1f, 0x8b}, func(r io.Reader) (io.Reader, error) {
+		return gzip.NewReader(r)
+	})
+}
+
+// trimCompressionExt returns the "path" without its compression extension, if any, i.e "topic.yaml.gz" to "topic.yaml".
+func trimCompressionExt(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+
+	decompressionsMu.RLock()
+	defer decompressionsMu.RUnlock()
+
+	for _, d := range decompressions {
+		if d.ext != "" && d.ext == ext {
+			return path[:len(path)-len(ext)]
+		}
+	}
+
+	return path
+}
+
+// decompress returns the decompressed "data" if it's compressed, based on the "path"'s extension or the contents' magic bytes,
+// otherwise it returns the "data" as it's.
+func decompress(path string, data []byte) ([]byte, error) {
+	return decompressWith(path, data, ioutil.ReadAll)
+}
+
+// decompressWith same as `decompress` but the decompressed stream is read by the "readAll",
+// i.e to limit the size of the decompressed contents and not only the compressed ones.
+func decompressWith(path string, data []byte, readAll func(io.Reader) ([]byte, error)) ([]byte, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+
+	decompressionsMu.RLock()
+	var decompressor Decompressor
+	for _, d := range decompressions {
+		if (d.ext != "" && d.ext == ext) || (len(d.magic) > 0 && bytes.HasPrefix(data, d.magic)) {
+			decompressor = d.decompressor
+			break
+		}
+	}
+	decompressionsMu.RUnlock()
+
+	if decompressor == nil {
+		return data, nil
+	}
+
+	r, err := decompressor(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	if closer, ok := r.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	return readAll(r)
+}
+
+const (
+	formatJSON = "json"
+	formatYAML = "yaml"
+	formatTOML = "toml"
+)
+
+// formatByExt returns the format of a file based on its extension, compression extensions are ignored.
+// It returns an empty string if the extension is missing or unknown.
+func formatByExt(path string) string {
+	switch strings.ToLower(filepath.Ext(trimCompressionExt(path))) {
+	case ".json":
+		return formatJSON
+	case ".yml", ".yaml":
+		return formatYAML
+	case ".toml":
+		return formatTOML
+	default:
+		return ""
+	}
+}
+
+var (
+	tomlKeyValueExpr = regexp.MustCompile(`^[A-Za-z0-9_."'-]+\s*=`)
+	tomlTableExpr    = regexp.MustCompile(`^\[{1,2}[A-Za-z0-9_."' -]+\]{1,2}$`)
+)
+
+// sniffFormat detects the format of the "data" based on its contents.
+// Valid json is json, contents that their first statement is a toml key/value pair or a table header are toml,
+// contents that start with '{' or '[' are json, so broken json documents report their syntax errors as such,
+// and anything else is yaml.
+func sniffFormat(data []byte) string {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // BOM.
+	if json.Valid(data) {
+		return formatJSON
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		if tomlKeyValueExpr.MatchString(line) || tomlTableExpr.MatchString(line) {
+			return formatTOML
+		}
+
+		if line[0] == '{' || line[0] == '[' {
+			return formatJSON
+		}
+
+		break
+	}
+
+	return formatYAML
+}
+
+// detectFormat returns the format of a file's "data", the contents are sniffed only if its extension is missing or unknown,
+// otherwise valid json is json and anything else is decoded by the extension's format,
+// so i.e yaml flow-style files (`{name: topic}`) are yaml and broken ".json" files report their json syntax errors.
+func detectFormat(path string, data []byte) string {
+	format := formatByExt(path)
+	if format == "" {
+		return sniffFormat(data)
+	}
+
+	if json.Valid(data) {
+		return formatJSON
+	}
+
+	return format
+}
+
+func unmarshalFormat(format string, data []byte, outPtr interface{}) error {
+	switch format {
+	case formatYAML:
+		return yaml.Unmarshal(data, outPtr)
+	case formatTOML:
+		return toml.Unmarshal(data, outPtr)
+	default:
+		return json.Unmarshal(data, outPtr)
+	}
+}
+
+func marshalFormat(format string, v interface{}) ([]byte, error) {
+	switch format {
+	case formatYAML:
+		return yaml.Marshal(v)
+	case formatTOML:
+		buf := new(bytes.Buffer)
+		if err := toml.NewEncoder(buf).Encode(v); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return json.Marshal(v)
+	}
+}
@@ -0,0 +1,72 @@
+package bite
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		path     string
+		data     string
+		expected string
+	}{
+		{"topic.json", `{"name": "topic"}`, formatJSON},
+		{"topic.yaml", `name: topic`, formatYAML},
+		{"topic.yaml.gz", `name: topic`, formatYAML},
+		{"topic.toml", `name = "topic"`, formatTOML},
+		{"/dev/stdin", `[{"name": "topic"}]`, formatJSON},
+		{"/dev/stdin", "# comment\nname: topic\npartitions: 1", formatYAML},
+		{"/dev/stdin", "# comment\n\n[configs]\nretention = 1", formatTOML},
+		{"/tmp/tmp.1234", `name = "topic"`, formatTOML},
+		{"topic.json", "name: topic", formatJSON},
+		{"topic.yaml", `{"name": "topic"}`, formatJSON},
+		// broken json is still json, so its syntax errors are reported as such.
+		{"topic.json", `{"name": "topic",}`, formatJSON},
+		{"/dev/stdin", "{\n  \"name\": \"topic\"", formatJSON},
+		{"/tmp/tmp.1234", `[{"name": "topic"},]`, formatJSON},
+		{"topic.txt", "\xef\xbb\xbf {\"name\": }", formatJSON},
+		{"topic.yaml", `{name: topic}`, formatYAML},
+		// only the contents of files with missing or unknown extensions are sniffed.
+		{"topic.yml", "[a, b]", formatYAML},
+		{"topic.yaml", "{name: topic,\n  partitions: 3}", formatYAML},
+		{"topic.toml", `[configs]`, formatTOML},
+	}
+
+	for i, tt := range tests {
+		if got := detectFormat(tt.path, []byte(tt.data)); got != tt.expected {
+			t.Fatalf("[%d] expected format of '%s' to be '%s' but got '%s'", i, tt.path, tt.expected, got)
+		}
+	}
+}
+
+func TestTryReadFileCompressed(t *testing.T) {
+	dir := t.TempDir()
+
+	buf := new(bytes.Buffer)
+	w := gzip.NewWriter(buf)
+	w.Write([]byte("name = \"topic\"\npartitions = 3\n"))
+	w.Close()
+
+	// no extension at all, both compression and format should be detected by the contents.
+	path := filepath.Join(dir, "resource")
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Name       string `toml:"name"`
+		Partitions int    `toml:"partitions"`
+	}
+
+	if err := TryReadFile("@"+path, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Name != "topic" || got.Partitions != 3 {
+		t.Fatalf("expected name 'topic' and partitions 3 but got '%s' and %d", got.Name, got.Partitions)
+	}
+}
@@ -1,24 +1,31 @@
 package bite
 
 import (
-	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v2"
 )
 
 type (
 	FileLoader struct {
-		elseFunc     func() error
-		pathResolver PathResolver
+		elseFunc      func() error
+		pathResolver  PathResolver
+		pathsResolver PathsResolver
+		merge         mergeOptions
+		recursive     bool
+		ignoreFlags   bool
+		template      bool
+		setValues     bool
 	}
 
 	PathResolver func(cmd *cobra.Command, args []string) string
+	// PathsResolver resolves more than one file paths, they are loaded and deep-merged in order, see `LoadFiles`.
+	PathsResolver func(cmd *cobra.Command, args []string) []string
 )
 
 func ElseBind(fn func() error) func(*FileLoader) {
@@ -37,15 +44,43 @@ func (fl *FileLoader) WithPathResolve(fn PathResolver) *FileLoader {
 	return fl
 }
 
-func FileBind(outPtr interface{}, customizers ...func(*FileLoader)) CobraRunner {
-	if outPtr == nil {
-		return emptyRunner
+// WithPathsResolve sets a resolver for more than one files, if it returns at least one path
+// then those files are deep-merged in order, otherwise the single path resolver is used instead.
+//
+// Defaults to the values of the `--file` flag, see `CanLoadFiles`.
+func (fl *FileLoader) WithPathsResolve(fn PathsResolver) *FileLoader {
+	fl.pathsResolver = fn
+	return fl
+}
+
+// MergeListsBind sets the list merge strategy of the overlay files, see `FileLoader.MergeLists`.
+func MergeListsBind(strategy MergeStrategy) func(*FileLoader) {
+	return func(fl *FileLoader) {
+		fl.MergeLists(strategy)
 	}
+}
 
-	if reflect.TypeOf(outPtr).Kind() != reflect.Ptr {
-		panic("outPtr is not a pointer")
+// MergeLists sets the strategy of how lists are merged when more than one file is loaded, defaults to `MergeReplace`.
+func (fl *FileLoader) MergeLists(strategy MergeStrategy) *FileLoader {
+	fl.merge.strategy = strategy
+	return fl
+}
+
+// MergeListsByKeyBind same as `MergeListsBind(MergeByKey)` but with a custom key field, see `FileLoader.MergeListsByKey`.
+func MergeListsByKeyBind(key string) func(*FileLoader) {
+	return func(fl *FileLoader) {
+		fl.MergeListsByKey(key)
 	}
+}
 
+// MergeListsByKey sets the `MergeByKey` strategy, list items are merged when their "key" field values are equal.
+func (fl *FileLoader) MergeListsByKey(key string) *FileLoader {
+	fl.merge.strategy = MergeByKey
+	fl.merge.key = key
+	return fl
+}
+
+func newFileLoader() *FileLoader {
 	fl := new(FileLoader)
 	fl.pathResolver = func(_ *cobra.Command, args []string) string {
 		if len(args) == 0 {
@@ -54,55 +89,104 @@ func FileBind(outPtr interface{}, customizers ...func(*FileLoader)) CobraRunner
 
 		return args[0]
 	}
+	fl.pathsResolver = func(cmd *cobra.Command, _ []string) []string {
+		return GetFilesFlag(cmd)
+	}
+	fl.merge.key = defaultMergeKey
 
-	for _, c := range customizers {
-		c(fl)
+	return fl
+}
+
+// overrideFlags returns a function which applies the explicitly set flags and then the `--set` values on top of the loaded value,
+// flag values are captured before loading because flags may be bound to the same value.
+func (fl *FileLoader) overrideFlags(cmd *cobra.Command, outPtr interface{}) func() error {
+	if fl.ignoreFlags {
+		return func() error {
+			return fl.applySetValues(cmd, outPtr)
+		}
 	}
 
-	return func(cmd *cobra.Command, args []string) error {
-		if path := fl.pathResolver(cmd, args); path != "" {
-			if err := LoadFile(cmd, path, outPtr); err != nil {
+	values := changedFlagValues(cmd)
+	return func() error {
+		applyFlagOverrides(outPtr, values)
+		return fl.applySetValues(cmd, outPtr)
+	}
+}
+
+func (fl *FileLoader) load(cmd *cobra.Command, args []string, outPtr interface{}) error {
+	resetFileFlags(cmd)
+
+	if fl.pathsResolver != nil {
+		if paths := fl.pathsResolver(cmd, args); len(paths) > 0 {
+			render, err := fl.renderer(cmd)
+			if err != nil {
 				return err
 			}
-		} else {
-			if fl.elseFunc != nil {
-				if err := fl.elseFunc(); err != nil {
-					return err
-				}
+
+			override := fl.overrideFlags(cmd, outPtr)
+			if err = loadFiles(cmd, paths, outPtr, fl.merge, render); err != nil {
+				return err
 			}
+
+			return override()
 		}
+	}
 
-		return nil
+	if path := fl.pathResolver(cmd, args); path != "" {
+		render, err := fl.renderer(cmd)
+		if err != nil {
+			return err
+		}
+
+		override := fl.overrideFlags(cmd, outPtr)
+		if err = loadFile(cmd, path, outPtr, render); err != nil {
+			return err
+		}
+
+		return override()
 	}
+
+	if fl.elseFunc != nil {
+		if err := fl.elseFunc(); err != nil {
+			return err
+		}
+	}
+
+	return fl.applySetValues(cmd, outPtr)
 }
 
-func ShouldTryLoadFile(cmd *cobra.Command, outPtr interface{}) *FileLoader {
+func FileBind(outPtr interface{}, customizers ...func(*FileLoader)) CobraRunner {
+	if outPtr == nil {
+		return emptyRunner
+	}
+
 	if reflect.TypeOf(outPtr).Kind() != reflect.Ptr {
 		panic("outPtr is not a pointer")
 	}
 
-	fl := new(FileLoader)
-	fl.pathResolver = func(c *cobra.Command, args []string) string {
-		if len(args) == 0 {
-			return ""
-		}
+	fl := newFileLoader()
 
-		return args[0]
+	for _, c := range customizers {
+		c(fl)
+	}
+
+	return func(cmd *cobra.Command, args []string) error {
+		return fl.load(cmd, args, outPtr)
+	}
+}
+
+func ShouldTryLoadFile(cmd *cobra.Command, outPtr interface{}) *FileLoader {
+	if reflect.TypeOf(outPtr).Kind() != reflect.Ptr {
+		panic("outPtr is not a pointer")
 	}
 
+	fl := newFileLoader()
+
 	oldRunE := cmd.RunE
 
 	cmd.RunE = func(c *cobra.Command, args []string) error {
-		if path := fl.pathResolver(c, args); path != "" {
-			if err := LoadFile(c, path, outPtr); err != nil {
-				return err
-			}
-		} else {
-			if fl.elseFunc != nil {
-				if err := fl.elseFunc(); err != nil {
-					return err
-				}
-			}
+		if err := fl.load(c, args, outPtr); err != nil {
+			return err
 		}
 
 		return oldRunE(c, args)
@@ -114,31 +198,164 @@ func ShouldTryLoadFile(cmd *cobra.Command, outPtr interface{}) *FileLoader {
 // LoadFile same as `tryReadFile` but it should be used for operations that we read the whole object from file,
 // not just a sub property of it like `--config ./configs.json`.
 //
-// It just prints a message to the user that we load from file, so we ignore the flags.
+// It just prints a message to the user that we load from file.
+// Decode errors are returned as `*FileDecodeError`, they point to the line and column of the failure.
+// Note that `FileBind` and `ShouldTryLoadFile` apply the explicitly set flags on top of the file's values afterwards,
+// i.e `mycli topic create -f t.yaml --partitions 12`, see `ApplyFlagOverrides`.
 func LoadFile(cmd *cobra.Command, path string, outPtr interface{}) error {
+	return loadFile(cmd, path, outPtr, nil)
+}
+
+func loadFile(cmd *cobra.Command, path string, outPtr interface{}, render contentRenderer) error {
 	if err := PrintInfo(cmd, "Loading from file '%s'", path); err != nil {
 		return err
 	}
 
-	return TryReadFile(path, outPtr)
+	format, data, err := loadDocument(cmd, path, outPtr, render)
+	if err != nil {
+		return err
+	}
+
+	recordFileFlags(cmd, func() interface{} {
+		var doc interface{}
+		if err := unmarshalFormat(format, data, &doc); err != nil {
+			return nil
+		}
+
+		return normalizeDocument(doc)
+	})
+
+	return nil
 }
 
 // TryReadFile will try to check if a flag value begins with 'flagFilePrefix'
-// if so, then it will json parse its contents, decode them and set to the `outPtr`,
-// otherwise it will decode the flagvalue using json unmarshaler and send the result to the `outPtr`.
+// if so, then it will parse its contents, decode them and set to the `outPtr`,
+// otherwise it will decode the flagvalue itself and send the result to the `outPtr`.
+//
+// The decoder (json, yaml or toml) is picked by the file extension,
+// if the extension is missing or misleading, i.e `@/dev/stdin`, then by the contents.
+// Compressed files, i.e ".gz", are decompressed first, see `RegisterDecompressor`.
 func TryReadFile(flagValue string, outPtr interface{}) (err error) {
+	_, err = readDocument(flagValue, outPtr)
+	return
+}
+
+// readDocument same as `TryReadFile` but it returns the detected format as well.
+func readDocument(flagValue string, outPtr interface{}) (string, error) {
 	result, err := TryReadFileContents(flagValue)
 	if err != nil {
+		return "", err
+	}
+
+	format := detectFormat(sourcePath(flagValue), result)
+	return format, unmarshalFormat(format, result, outPtr)
+}
+
+// contentRenderer transforms the contents of a file before decoding, see `FileLoader.Template`.
+type contentRenderer func(path string, data []byte) ([]byte, error)
+
+// loadDocument same as `readDocument` but the contents are rendered by the "render", if not nil,
+// and decode errors are wrapped into a `FileDecodeError`. It returns the (rendered) contents as well.
+func loadDocument(cmd *cobra.Command, path string, outPtr interface{}, render contentRenderer) (string, []byte, error) {
+	data, err := TryReadFileContents(path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if render != nil {
+		if data, err = render(sourcePath(path), data); err != nil {
+			return "", nil, err
+		}
+	}
+
+	format := detectFormat(sourcePath(path), data)
+	if err = unmarshalFormat(format, data, outPtr); err != nil {
+		return format, data, newFileDecodeError(cmd, sourcePath(path), format, data, err)
+	}
+
+	return format, data, nil
+}
+
+const (
+	filesFlagKey       = "file"
+	printMergedFlagKey = "print-merged"
+	defaultMergeKey    = "name"
+)
+
+// CanLoadFiles registers the repeatable `--file, -f` flag to the "cmd" command,
+// i.e `-f base.yaml -f prod.yaml`, the files are deep-merged in order by `FileBind` and `ShouldTryLoadFile`.
+// It registers the `--print-merged` flag as well, useful for debugging the merged result.
+func CanLoadFiles(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().StringArrayP(filesFlagKey, string(filesFlagKey[0]), nil, "Load the resource from file, can be repeated to overlay files in order")
+	cmd.Flags().Bool(printMergedFlagKey, false, "Print the merged result of the --file overlays to the standard error before running the command")
+	cmd.MarkFlagFilename(filesFlagKey)
+	return cmd
+}
+
+// GetFilesFlag returns the values of the `--file` flag, however if not registered it returns nil.
+func GetFilesFlag(cmd *cobra.Command) []string {
+	paths, _ := cmd.Flags().GetStringArray(filesFlagKey)
+	return paths
+}
+
+// LoadFiles reads the files of the "paths" in order and deep-merges them into the "outPtr",
+// each file overlays the previous ones, objects are merged per key and lists are merged based on the "strategy".
+// The "key" is the field name that matches list items on `MergeByKey`, i.e "name".
+//
+// The merged document is decoded using the format of the first (base) file.
+func LoadFiles(cmd *cobra.Command, paths []string, outPtr interface{}, strategy MergeStrategy, key string) error {
+	return loadFiles(cmd, paths, outPtr, mergeOptions{strategy: strategy, key: key}, nil)
+}
+
+func loadFiles(cmd *cobra.Command, paths []string, outPtr interface{}, opts mergeOptions, render contentRenderer) error {
+	if len(paths) == 1 {
+		return loadFile(cmd, paths[0], outPtr, render)
+	}
+
+	if err := PrintInfo(cmd, "Loading from files '%s'", strings.Join(paths, "', '")); err != nil {
 		return err
 	}
 
-	ext := filepath.Ext(flagValue)
-	switch ext {
-	case ".yml", ".yaml":
-		return yaml.Unmarshal(result, outPtr)
-	default:
-		return json.Unmarshal(result, outPtr)
+	var (
+		merged     interface{}
+		baseFormat string
+	)
+
+	for i, path := range paths {
+		var doc interface{}
+		format, _, err := loadDocument(cmd, path, &doc, render)
+		if err != nil {
+			return err
+		}
+
+		if i == 0 {
+			baseFormat = format
+		}
+
+		merged = mergeDocuments(merged, normalizeDocument(doc), opts)
 	}
+
+	if printMerged, _ := cmd.Flags().GetBool(printMergedFlagKey); printMerged {
+		var err error
+		if strings.ToUpper(GetOutPutFlag(cmd)) == "JSON" {
+			err = WriteJSON(cmd.ErrOrStderr(), merged, true, "")
+		} else {
+			err = WriteYAML(cmd.ErrOrStderr(), merged)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	recordFileFlags(cmd, func() interface{} { return merged })
+
+	b, err := marshalFormat(baseFormat, merged)
+	if err != nil {
+		return err
+	}
+
+	return unmarshalFormat(baseFormat, b, outPtr)
 }
 
 var errFlagMissing = fmt.Errorf("flag value is missing")
@@ -154,7 +371,9 @@ func AllowEmptyFlag(err error) error {
 const flagFilePrefix = '@'
 
 // TryReadFileContents will try to check if a flag value begins with 'flagFilePrefix'
-// if so then it returns the contents of the filename given from the flagValue after the 'flagFilePrefix' character.
+// if so then it returns the contents of the filename given from the flagValue after the 'flagFilePrefix' character,
+// decompressed if the file is compressed.
+// The filename can be an url as well, i.e `@https://...` or `@file://...`, see `DefaultRemoteSource`.
 // Otherwise returns the flagValue as raw slice of bytes.
 func TryReadFileContents(flagValue string) ([]byte, error) {
 	if len(flagValue) == 0 {
@@ -175,6 +394,10 @@ func TryReadFileContents(flagValue string) ([]byte, error) {
 		}
 
 		pathname = flagValue[1:]
+		if IsRemoteSource(pathname) {
+			return DefaultRemoteSource.Read(pathname)
+		}
+
 		if !filepath.IsAbs(pathname) {
 			if abspath, err := filepath.Abs(pathname); err == nil {
 				pathname = abspath
@@ -182,5 +405,10 @@ func TryReadFileContents(flagValue string) ([]byte, error) {
 		}
 	}
 
-	return ioutil.ReadFile(pathname)
+	b, err := ioutil.ReadFile(pathname)
+	if err != nil {
+		return nil, err
+	}
+
+	return decompress(pathname, b)
 }
@@ -0,0 +1,138 @@
+package bite
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// MergeStrategy describes how lists are merged when more than one file is loaded, see `LoadFiles`.
+type MergeStrategy uint8
+
+const (
+	// MergeReplace replaces the previous list with the overlay's one, this is the default behavior.
+	MergeReplace MergeStrategy = iota
+	// MergeAppend appends the overlay's list items to the previous list.
+	MergeAppend
+	// MergeByKey merges object items of the two lists that share the same value of a key field, i.e "name",
+	// items that can not be matched are appended.
+	MergeByKey
+)
+
+func (s MergeStrategy) String() string {
+	switch s {
+	case MergeAppend:
+		return "append"
+	case MergeByKey:
+		return "merge-by-key"
+	default:
+		return "replace"
+	}
+}
+
+// mergeOptions keeps the list merge strategy and the key field used on `MergeByKey`.
+type mergeOptions struct {
+	strategy MergeStrategy
+	key      string
+}
+
+// normalizeDocument converts the yaml's map[interface{}]interface{} values to map[string]interface{} ones,
+// so the result can be merged with (and encoded as) json documents as well.
+func normalizeDocument(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(vv))
+		for key, value := range vv {
+			m[fmt.Sprintf("%v", key)] = normalizeDocument(value)
+		}
+		return m
+	case map[string]interface{}:
+		for key, value := range vv {
+			vv[key] = normalizeDocument(value)
+		}
+		return vv
+	case []interface{}:
+		for i, value := range vv {
+			vv[i] = normalizeDocument(value)
+		}
+		return vv
+	default:
+		return v
+	}
+}
+
+// mergeDocuments deep-merges the "overlay" on top of the "base" document and returns the result.
+// Objects are merged per key, lists are merged based on the "opts" and any other value of the "overlay" replaces the "base" one.
+func mergeDocuments(base, overlay interface{}, opts mergeOptions) interface{} {
+	switch overlayValue := overlay.(type) {
+	case map[string]interface{}:
+		baseValue, ok := base.(map[string]interface{})
+		if !ok {
+			return overlayValue
+		}
+
+		for key, value := range overlayValue {
+			if existing, exists := baseValue[key]; exists {
+				baseValue[key] = mergeDocuments(existing, value, opts)
+				continue
+			}
+
+			baseValue[key] = value
+		}
+
+		return baseValue
+	case []interface{}:
+		baseValue, ok := base.([]interface{})
+		if !ok {
+			return overlayValue
+		}
+
+		return mergeLists(baseValue, overlayValue, opts)
+	default:
+		return overlay
+	}
+}
+
+func mergeLists(base, overlay []interface{}, opts mergeOptions) []interface{} {
+	switch opts.strategy {
+	case MergeAppend:
+		return append(base, overlay...)
+	case MergeByKey:
+		for _, item := range overlay {
+			idx := indexOfKey(base, item, opts.key)
+			if idx == -1 {
+				base = append(base, item)
+				continue
+			}
+
+			base[idx] = mergeDocuments(base[idx], item, opts)
+		}
+
+		return base
+	default:
+		return overlay
+	}
+}
+
+// indexOfKey returns the index of the "list"'s object which its "key" field's value
+// is equal to the "item"'s one, if not found or "item" is not an object then it returns -1.
+func indexOfKey(list []interface{}, item interface{}, key string) int {
+	obj, ok := item.(map[string]interface{})
+	if !ok {
+		return -1
+	}
+
+	value, ok := obj[key]
+	if !ok {
+		return -1
+	}
+
+	for i, existing := range list {
+		if existingObj, ok := existing.(map[string]interface{}); ok {
+			if existingValue, ok := existingObj[key]; ok && reflect.DeepEqual(existingValue, value) {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,83 @@
+package bite
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+type testMergeResource struct {
+	Name       string            `json:"name" yaml:"name"`
+	Partitions int               `json:"partitions" yaml:"partitions"`
+	Configs    map[string]string `json:"configs" yaml:"configs"`
+	Tags       []string          `json:"tags" yaml:"tags"`
+	Consumers  []struct {
+		Name  string `json:"name" yaml:"name"`
+		Group string `json:"group" yaml:"group"`
+	} `json:"consumers" yaml:"consumers"`
+}
+
+func TestLoadFiles(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"base.yaml": `name: topic
+partitions: 1
+configs:
+  cleanup.policy: delete
+  retention.ms: "1000"
+tags: [a, b]
+consumers:
+  - name: c1
+    group: g1
+  - name: c2
+    group: g2
+`,
+		"prod.json": `{
+  "partitions": 12,
+  "configs": {"retention.ms": "5000"},
+  "tags": ["c"],
+  "consumers": [{"name": "c2", "group": "g2-prod"}, {"name": "c3", "group": "g3"}]
+}`,
+	})
+	base, prod := filepath.Join(dir, "base.yaml"), filepath.Join(dir, "prod.json")
+
+	tests := []struct {
+		strategy          MergeStrategy
+		expectedTags      []string
+		expectedConsumers []string
+	}{
+		{MergeReplace, []string{"c"}, []string{"c2:g2-prod", "c3:g3"}},
+		{MergeAppend, []string{"a", "b", "c"}, []string{"c1:g1", "c2:g2", "c2:g2-prod", "c3:g3"}},
+		{MergeByKey, []string{"a", "b", "c"}, []string{"c1:g1", "c2:g2-prod", "c3:g3"}},
+	}
+
+	for _, tt := range tests {
+		var got testMergeResource
+		if err := LoadFiles(&cobra.Command{}, []string{base, prod}, &got, tt.strategy, "name"); err != nil {
+			t.Fatalf("[%s] %v", tt.strategy, err)
+		}
+
+		if got.Name != "topic" || got.Partitions != 12 {
+			t.Fatalf("[%s] expected name 'topic' and partitions 12 but got '%s' and %d", tt.strategy, got.Name, got.Partitions)
+		}
+
+		expectedConfigs := map[string]string{"cleanup.policy": "delete", "retention.ms": "5000"}
+		if !reflect.DeepEqual(got.Configs, expectedConfigs) {
+			t.Fatalf("[%s] expected configs %v but got %v", tt.strategy, expectedConfigs, got.Configs)
+		}
+
+		if !reflect.DeepEqual(got.Tags, tt.expectedTags) {
+			t.Fatalf("[%s] expected tags %v but got %v", tt.strategy, tt.expectedTags, got.Tags)
+		}
+
+		var consumers []string
+		for _, c := range got.Consumers {
+			consumers = append(consumers, c.Name+":"+c.Group)
+		}
+
+		if !reflect.DeepEqual(consumers, tt.expectedConsumers) {
+			t.Fatalf("[%s] expected consumers %v but got %v", tt.strategy, tt.expectedConsumers, consumers)
+		}
+	}
+}
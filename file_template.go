@@ -0,0 +1,255 @@
+package bite
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// templateSetFlagKey is the repeatable `--set-value key=value` flag, its values are available to the templated files as `.Values.key`.
+	templateSetFlagKey = "set-value"
+	// valuesFlagKey is the repeatable `--values values.yaml` flag, the files are merged in order and the `--set-value` values are applied on top of them.
+	valuesFlagKey = "values"
+)
+
+// CanTemplateFiles registers the `--set-value` and `--values` flags to the "cmd" command,
+// their values are used to render the files that are loaded as templates, see `FileLoader.Template`.
+func CanTemplateFiles(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().StringArray(templateSetFlagKey, nil, "Set a template value, i.e --set-value tenant.name=acme, can be repeated")
+	cmd.Flags().StringArray(valuesFlagKey, nil, "Load template values from a file, can be repeated, the --set-value values take precedence")
+	cmd.MarkFlagFilename(valuesFlagKey)
+	return cmd
+}
+
+// TemplateBind makes the file loader to render the loaded files as templates, see `FileLoader.Template`.
+func TemplateBind() func(*FileLoader) {
+	return func(fl *FileLoader) {
+		fl.Template()
+	}
+}
+
+// Template makes the file loader to treat the loaded files as Go templates, similar to Helm,
+// they are rendered with the `--values` and `--set-value` flag values before decoding, i.e `name: {{ .Values.tenant }}-connector`.
+// The flags should be registered through `CanTemplateFiles`.
+func (fl *FileLoader) Template() *FileLoader {
+	fl.template = true
+	return fl
+}
+
+func (fl *FileLoader) renderer(cmd *cobra.Command) (contentRenderer, error) {
+	if !fl.template {
+		return nil, nil
+	}
+
+	values, err := GetTemplateValues(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(path string, data []byte) ([]byte, error) {
+		return RenderTemplate(path, data, values)
+	}, nil
+}
+
+// GetTemplateValues returns the merged values of the `--values` files and the `--set-value` flags of the "cmd" command.
+func GetTemplateValues(cmd *cobra.Command) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+
+	files, _ := cmd.Flags().GetStringArray(valuesFlagKey)
+	for _, path := range files {
+		var doc interface{}
+		if _, _, err := loadDocument(cmd, path, &doc, nil); err != nil {
+			return nil, err
+		}
+
+		fileValues, ok := normalizeDocument(doc).(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("values file '%s' is not an object", path)
+		}
+
+		values = mergeDocuments(values, fileValues, mergeOptions{}).(map[string]interface{})
+	}
+
+	assignments, _ := cmd.Flags().GetStringArray(templateSetFlagKey)
+	for _, assignment := range assignments {
+		if err := ParseSetValue(values, assignment); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// ParseSetValue parses a `key=value` assignment, where key can be a dotted path, i.e `tenant.name=acme`,
+// and sets the value to the "values", nested objects are created if missing.
+// Numbers, booleans and null are converted to their types, use quotes to keep them as strings, i.e `version="1"`.
+func ParseSetValue(values map[string]interface{}, assignment string) error {
+	idx := strings.IndexByte(assignment, '=')
+	if idx <= 0 {
+		return fmt.Errorf("invalid value '%s', expected key=value", assignment)
+	}
+
+	keys := strings.Split(assignment[:idx], ".")
+	value := parseSetValueType(assignment[idx+1:])
+
+	m := values
+	for i, key := range keys {
+		if key == "" {
+			return fmt.Errorf("invalid key '%s', empty path segment", assignment[:idx])
+		}
+
+		if i == len(keys)-1 {
+			m[key] = value
+			break
+		}
+
+		next, ok := m[key].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			m[key] = next
+		}
+
+		m = next
+	}
+
+	return nil
+}
+
+func parseSetValueType(s string) interface{} {
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+
+	switch s {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+
+	return s
+}
+
+// TemplateFuncs are the functions that are available to the templated files, they can be modified by the application.
+var TemplateFuncs = template.FuncMap{
+	"default": func(def interface{}, value interface{}) interface{} {
+		if value == nil {
+			return def
+		}
+
+		if s, ok := value.(string); ok && s == "" {
+			return def
+		}
+
+		return value
+	},
+	"required": func(msg string, value interface{}) (interface{}, error) {
+		if value == nil {
+			return nil, fmt.Errorf("%s", msg)
+		}
+
+		if s, ok := value.(string); ok && s == "" {
+			return nil, fmt.Errorf("%s", msg)
+		}
+
+		return value, nil
+	},
+	"quote": func(value interface{}) string {
+		return strconv.Quote(fmt.Sprintf("%v", value))
+	},
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"trim":  strings.TrimSpace,
+	"env":   os.Getenv,
+	"toJson": func(value interface{}) (string, error) {
+		b, err := MarshalJSON(value, false)
+		return string(b), err
+	},
+	"toYaml": func(value interface{}) (string, error) {
+		b, err := yaml.Marshal(value)
+		return strings.TrimSuffix(string(b), "\n"), err
+	},
+}
+
+// RenderTemplate renders the "data" as a Go template, the "values" are available as `.Values`.
+// Missing values render as empty, like Helm, use the `required` function to fail on them instead.
+func RenderTemplate(name string, data []byte, values map[string]interface{}) ([]byte, error) {
+	tmpl, err := template.New(name).Option("missingkey=zero").Funcs(TemplateFuncs).
+		Funcs(template.FuncMap{printableFuncName: printable}).Parse(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range tmpl.Templates() { // including the defined ones.
+		if t.Tree != nil {
+			emptyMissingValues(t.Tree.Root)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err = tmpl.Execute(buf, map[string]interface{}{"Values": values}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// printableFuncName is the template function that is appended to the actions that print a value, see `emptyMissingValues`.
+const printableFuncName = "bitePrintable"
+
+func printable(value interface{}) interface{} {
+	if value == nil {
+		return ""
+	}
+
+	return value
+}
+
+// emptyMissingValues makes the actions of the "node" to print the missing (nil) values as empty instead of "<no value>",
+// the text of the file is kept as it is.
+func emptyMissingValues(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+
+		for _, child := range n.Nodes {
+			emptyMissingValues(child)
+		}
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) > 0 { // variable declarations and assignments do not print.
+			return
+		}
+
+		printableFunc := parse.NewIdentifier(printableFuncName).SetPos(n.Pos)
+		n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{NodeType: parse.NodeCommand, Pos: n.Pos, Args: []parse.Node{printableFunc}})
+	case *parse.IfNode:
+		emptyMissingValues(n.List)
+		emptyMissingValues(n.ElseList)
+	case *parse.RangeNode:
+		emptyMissingValues(n.List)
+		emptyMissingValues(n.ElseList)
+	case *parse.WithNode:
+		emptyMissingValues(n.List)
+		emptyMissingValues(n.ElseList)
+	}
+}
@@ -0,0 +1,110 @@
+package bite
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	values := map[string]interface{}{
+		"tenant": "acme",
+		"labels": map[string]interface{}{"team": "data"},
+		"empty":  nil,
+	}
+
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{"value", "name: {{ .Values.tenant }}-connector", "name: acme-connector"},
+		{"nested value", "team: {{ .Values.labels.team }}", "team: data"},
+		{"missing value", "name: {{ .Values.missing }}", "name: "},
+		{"nil value", "name: {{ .Values.empty }}", "name: "},
+		{"default", `name: {{ .Values.missing | default "topic" }}`, "name: topic"},
+		{"text is kept", "# <no value> is not replaced\nname: {{ .Values.tenant }}", "# <no value> is not replaced\nname: acme"},
+		{"branches", "{{ if .Values.tenant }}{{ .Values.missing }}{{ else }}x{{ end }}{{ range $k, $v := .Values.labels }}{{ $k }}={{ $v }}{{ end }}", "team=data"},
+		{"variables", "{{ $name := .Values.missing }}name: {{ $name }}", "name: "},
+		{"defined", `{{ define "name" }}{{ .Values.missing }}{{ end }}name: {{ template "name" . }}`, "name: "},
+	}
+
+	for _, tt := range tests {
+		got, err := RenderTemplate(tt.name, []byte(tt.data), values)
+		if err != nil {
+			t.Fatalf("[%s] %v", tt.name, err)
+		}
+
+		if string(got) != tt.expected {
+			t.Fatalf("[%s] expected %q but got %q", tt.name, tt.expected, got)
+		}
+	}
+
+	if _, err := RenderTemplate("required", []byte(`{{ .Values.missing | required "tenant is required" }}`), values); err == nil {
+		t.Fatal("expected the required function to fail")
+	}
+}
+
+func TestParseSetValue(t *testing.T) {
+	values := make(map[string]interface{})
+
+	for _, assignment := range []string{
+		"tenant.name=acme",
+		"tenant.id=42",
+		"replicas=1.5",
+		"enabled=true",
+		"owner=null",
+		`version="1"`,
+		"url=http://host?a=b",
+	} {
+		if err := ParseSetValue(values, assignment); err != nil {
+			t.Fatalf("%s: %v", assignment, err)
+		}
+	}
+
+	expected := map[string]interface{}{
+		"tenant":   map[string]interface{}{"name": "acme", "id": int64(42)},
+		"replicas": 1.5,
+		"enabled":  true,
+		"owner":    nil,
+		"version":  "1",
+		"url":      "http://host?a=b",
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Fatalf("expected values %#v but got %#v", expected, values)
+	}
+
+	for _, assignment := range []string{"name", "=value", "tenant..name=acme"} {
+		if err := ParseSetValue(values, assignment); err == nil {
+			t.Fatalf("%s: expected an error", assignment)
+		}
+	}
+}
+
+func TestGetTemplateValues(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"base.yaml": "tenant: acme\nlabels:\n  team: data\n  env: dev\n",
+		"prod.json": `{"labels": {"env": "prod"}}`,
+	})
+	base, prod := filepath.Join(dir, "base.yaml"), filepath.Join(dir, "prod.json")
+
+	cmd := CanTemplateFiles(&cobra.Command{Use: "apply"})
+	if err := cmd.ParseFlags([]string{"--values", base, "--values", prod, "--set-value", "labels.team=streams"}); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := GetTemplateValues(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"tenant": "acme",
+		"labels": map[string]interface{}{"team": "streams", "env": "prod"},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Fatalf("expected values %#v but got %#v", expected, values)
+	}
+}
@@ -0,0 +1,172 @@
+package bite
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// BindFlags registers a flag for each field of the "ptr" struct that is tagged with `flag`, i.e:
+//
+//	type TopicOptions struct {
+//		Name       string `flag:"name,n" usage:"The topic name" required:"true"`
+//		Partitions int    `flag:"partitions,p" usage:"The number of partitions" default:"1"`
+//		Config     struct {
+//			RetentionMs int64 `flag:"retention-ms" usage:"The retention in milliseconds"`
+//		} `flag:"config"` // registers the --config-retention-ms flag.
+//	}
+//
+// The `flag` tag is the flag name followed by an optional shorthand,
+// the `default` tag is the default value, if missing the field's current value is the default one,
+// nested structs are walked and their flags are prefixed by the struct field's `flag` tag name, if any.
+//
+// It returns a `CobraRunner` which checks the `required:"true"` fields through `CheckRequiredFlagNames`,
+// so a flag explicitly set to its zero value or a key of the loaded file is accepted, it should run after any file loading, i.e `Join(FileBind(&opts), BindFlags(cmd, &opts), run)`.
+func BindFlags(cmd *cobra.Command, ptr interface{}) CobraRunner {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		panic("ptr is not a pointer to a struct")
+	}
+
+	var required []string
+	if err := bindFlags(cmd.Flags(), v.Elem(), "", &required); err != nil {
+		panic(err)
+	}
+
+	if len(required) == 0 {
+		return emptyRunner
+	}
+
+	return RequireFlagNames(required...)
+}
+
+func bindFlags(set *pflag.FlagSet, v reflect.Value, prefix string, required *[]string) error {
+	typ := v.Type()
+	for i, n := 0, typ.NumField(); i < n; i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" && !field.Anonymous { // unexported.
+			continue
+		}
+
+		tag := field.Tag.Get("flag")
+		if tag == "-" {
+			continue
+		}
+
+		name, shorthand := tag, ""
+		if idx := strings.IndexByte(tag, ','); idx != -1 {
+			name, shorthand = tag[:idx], tag[idx+1:]
+		}
+
+		fieldValue := v.Field(i)
+
+		if isNestedFlagStruct(field.Type) {
+			nestedPrefix := prefix
+			if name != "" {
+				nestedPrefix += name + "-"
+			}
+
+			if err := bindFlags(set, fieldValue, nestedPrefix, required); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if name == "" {
+			continue
+		}
+
+		name = prefix + name
+
+		if field.Type == timeTyp {
+			// time values are parsed through their `layout` tag, i.e `layout:"2006-01-02"`.
+			flagVar := NewFlagVar(fieldValue.Addr().Interface()).WithLayout(field.Tag.Get("layout"))
+			if def, ok := field.Tag.Lookup("default"); ok && def != "" {
+				if err := flagVar.Set(def); err != nil {
+					return fmt.Errorf("flag %s: invalid default value '%s': %v", name, def, err)
+				}
+			}
+
+			set.VarP(flagVar, name, shorthand, field.Tag.Get("usage"))
+		} else {
+			if def, ok := field.Tag.Lookup("default"); ok {
+				if err := setValueFromString(fieldValue, def); err != nil {
+					return fmt.Errorf("flag %s: invalid default value '%s': %v", name, def, err)
+				}
+			}
+
+			registerFlag(set, fieldValue.Addr().Interface(), name, shorthand, field.Tag.Get("usage"))
+		}
+
+		if ok, _ := strconv.ParseBool(field.Tag.Get("required")); ok {
+			*required = append(*required, name)
+		}
+	}
+
+	return nil
+}
+
+var ipNetTyp = reflect.TypeOf(net.IPNet{})
+
+// isNestedFlagStruct reports whether a field's type is a struct of more flags and not a flag value itself.
+func isNestedFlagStruct(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && typ != timeTyp && typ != ipNetTyp
+}
+
+// registerFlag registers the "ptr" as a flag, through the pflag's typed functions if possible, otherwise through a `FlagVar`.
+// The current value of the "ptr" is the flag's default value.
+func registerFlag(set *pflag.FlagSet, ptr interface{}, name, shorthand, usage string) {
+	switch p := ptr.(type) {
+	case *string:
+		set.StringVarP(p, name, shorthand, *p, usage)
+	case *bool:
+		set.BoolVarP(p, name, shorthand, *p, usage)
+	case *int:
+		set.IntVarP(p, name, shorthand, *p, usage)
+	case *int8:
+		set.Int8VarP(p, name, shorthand, *p, usage)
+	case *int16:
+		set.Int16VarP(p, name, shorthand, *p, usage)
+	case *int32:
+		set.Int32VarP(p, name, shorthand, *p, usage)
+	case *int64:
+		set.Int64VarP(p, name, shorthand, *p, usage)
+	case *uint:
+		set.UintVarP(p, name, shorthand, *p, usage)
+	case *uint8:
+		set.Uint8VarP(p, name, shorthand, *p, usage)
+	case *uint16:
+		set.Uint16VarP(p, name, shorthand, *p, usage)
+	case *uint32:
+		set.Uint32VarP(p, name, shorthand, *p, usage)
+	case *uint64:
+		set.Uint64VarP(p, name, shorthand, *p, usage)
+	case *float32:
+		set.Float32VarP(p, name, shorthand, *p, usage)
+	case *float64:
+		set.Float64VarP(p, name, shorthand, *p, usage)
+	case *time.Duration:
+		set.DurationVarP(p, name, shorthand, *p, usage)
+	case *[]string:
+		set.StringSliceVarP(p, name, shorthand, *p, usage)
+	case *[]int:
+		set.IntSliceVarP(p, name, shorthand, *p, usage)
+	case *[]bool:
+		set.BoolSliceVarP(p, name, shorthand, *p, usage)
+	case *map[string]string:
+		set.StringToStringVarP(p, name, shorthand, *p, usage)
+	case *net.IP:
+		set.IPVarP(p, name, shorthand, *p, usage)
+	default:
+		set.VarP(NewFlagVar(ptr), name, shorthand, usage)
+		if indirectType(reflect.TypeOf(ptr)).Kind() == reflect.Bool {
+			set.Lookup(name).NoOptDefVal = "true"
+		}
+	}
+}
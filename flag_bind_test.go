@@ -0,0 +1,75 @@
+package bite
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+type testBindOptions struct {
+	Name       string        `json:"name" flag:"name,n" usage:"The topic name" required:"true"`
+	Partitions int           `json:"partitions" flag:"partitions,p" default:"1"`
+	Timeout    time.Duration `json:"timeout" flag:"timeout" default:"5s"`
+	Config     struct {
+		RetentionMs int64 `json:"retentionMs" flag:"retention-ms" required:"true"`
+	} `json:"config" flag:"config"`
+}
+
+func newTestBindCommand(opts *testBindOptions) *cobra.Command {
+	cmd := CanLoadFiles(&cobra.Command{Use: "create", Example: "create --name=topic --config-retention-ms=1000"})
+	cmd.RunE = Join(FileBind(opts), BindFlags(cmd, opts))
+	cmd.SetOutput(ioutil.Discard)
+	return cmd
+}
+
+func TestBindFlags(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{"topic.json": `{"name": "topic", "config": {"retentionMs": 1000}}`})
+	path := filepath.Join(dir, "topic.json")
+
+	var opts testBindOptions
+	newTestBindCommand(&opts)
+	if opts.Partitions != 1 || opts.Timeout != 5*time.Second {
+		t.Fatalf("expected defaults to be set but got partitions %d and timeout %s", opts.Partitions, opts.Timeout)
+	}
+
+	tests := []struct {
+		name                string
+		args                []string
+		expectedName        string
+		expectedPartitions  int
+		expectedRetentionMs int64
+		expectedErr         string
+	}{
+		{"missing", []string{"-p", "3"}, "", 0, 0, `required flags "name" and "config-retention-ms" not set`},
+		{"flags", []string{"-n", "topic", "-p", "3", "--config-retention-ms", "1000"}, "topic", 3, 1000, ""},
+		{"zero values", []string{"-n", "", "--config-retention-ms", "0"}, "", 1, 0, ""},
+		{"file", []string{"-f", path}, "topic", 1, 1000, ""},
+	}
+
+	for _, tt := range tests {
+		var opts testBindOptions
+
+		cmd := newTestBindCommand(&opts)
+		cmd.SetArgs(tt.args)
+		err := cmd.Execute()
+
+		if tt.expectedErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Fatalf("[%s] expected error %q but got: %v", tt.name, tt.expectedErr, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("[%s] %v", tt.name, err)
+		}
+
+		if opts.Name != tt.expectedName || opts.Partitions != tt.expectedPartitions || opts.Config.RetentionMs != tt.expectedRetentionMs {
+			t.Fatalf("[%s] unexpected values: %#v", tt.name, opts)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package bite
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// EnumFlag is a string `pflag.Value` that accepts only a closed set of values, i.e cleanup policies or compression types.
+// Use `EnumFlagVar` to register it to a command.
+type EnumFlag struct {
+	value           *string
+	allowed         []string
+	caseInsensitive bool
+}
+
+// NewEnumFlag returns a new `EnumFlag` which sets its value to the "ptr", its current value is the default one.
+func NewEnumFlag(ptr *string, allowed ...string) *EnumFlag {
+	return &EnumFlag{value: ptr, allowed: allowed}
+}
+
+// CaseInsensitive makes the enum to accept values in any case, the value is set as it's declared in the allowed ones.
+func (e *EnumFlag) CaseInsensitive() *EnumFlag {
+	e.caseInsensitive = true
+	return e
+}
+
+// Allowed returns the allowed values.
+func (e *EnumFlag) Allowed() []string {
+	return e.allowed
+}
+
+func (e *EnumFlag) String() string {
+	if e.value == nil {
+		return ""
+	}
+
+	return *e.value
+}
+
+func (e *EnumFlag) Set(v string) error {
+	for _, allowed := range e.allowed {
+		if allowed == v || (e.caseInsensitive && strings.EqualFold(allowed, v)) {
+			*e.value = allowed
+			return nil
+		}
+	}
+
+	allowed := strings.Join(e.allowed, ", ")
+	if suggestion := e.suggest(v); suggestion != "" {
+		return fmt.Errorf("did you mean %s? allowed values are: %s", strconv.Quote(suggestion), allowed)
+	}
+
+	// the value itself is part of the flag set's error, i.e `invalid argument "x" for "--policy" flag: allowed values are: delete, compact`.
+	return fmt.Errorf("allowed values are: %s", allowed)
+}
+
+// Type returns "string", so `GetString` of the flag set can be used to retrieve its value.
+func (e *EnumFlag) Type() string {
+	return "string"
+}
+
+// suggest returns the closest allowed value of "v", if any.
+func (e *EnumFlag) suggest(v string) (suggestion string) {
+	v = strings.ToLower(v)
+	minDistance := -1
+
+	for _, allowed := range e.allowed {
+		lower := strings.ToLower(allowed)
+		if v != "" && strings.HasPrefix(lower, v) {
+			return allowed
+		}
+
+		// same as cobra's command suggestions.
+		if d := levenshteinDistance(v, lower); d <= 2 && (minDistance == -1 || d < minDistance) {
+			minDistance = d
+			suggestion = allowed
+		}
+	}
+
+	return
+}
+
+func levenshteinDistance(s, t string) int {
+	a, b := []rune(s), []rune(t)
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			cur[j] = minInt(prev[j]+1, minInt(cur[j-1]+1, prev[j-1]+cost))
+		}
+		prev = cur
+	}
+
+	return prev[len(b)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+// EnumFlagVar registers the "enum" flag to the "cmd" command,
+// the allowed values are listed in the usage and they are registered for shell completion too.
+// It panics if the enum's default value, if not empty, is not one of the allowed values.
+func EnumFlagVar(cmd *cobra.Command, enum *EnumFlag, name, shorthand, usage string) *EnumFlag {
+	if def := enum.String(); def != "" {
+		if err := enum.Set(def); err != nil {
+			panic(fmt.Sprintf("flag %s: invalid default value '%s': %v", name, def, err))
+		}
+	}
+
+	usage = fmt.Sprintf("%s, one of: %s", usage, strings.Join(enum.allowed, ", "))
+	cmd.Flags().VarP(enum, name, shorthand, usage)
+
+	cmd.RegisterFlagCompletionFunc(name, func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var completions []string
+		for _, allowed := range enum.allowed {
+			if strings.HasPrefix(strings.ToLower(allowed), strings.ToLower(toComplete)) {
+				completions = append(completions, allowed)
+			}
+		}
+
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	return enum
+}
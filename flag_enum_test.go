@@ -0,0 +1,77 @@
+package bite
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestEnumFlag(t *testing.T) {
+	tests := []struct {
+		caseInsensitive bool
+		value           string
+		expected        string
+		expectedErr     string
+	}{
+		{false, "compact", "compact", ""},
+		{false, "Compact", "", `did you mean "compact"? allowed values are: delete, compact, compact,delete`},
+		{true, "Compact", "compact", ""},
+		{true, "COMPACT,DELETE", "compact,delete", ""},
+		{false, "del", "", `did you mean "delete"? allowed values are: delete, compact, compact,delete`},
+		{false, "compcat", "", `did you mean "compact"? allowed values are: delete, compact, compact,delete`},
+		{false, "retain", "", "allowed values are: delete, compact, compact,delete"},
+	}
+
+	for _, tt := range tests {
+		var policy string
+		enum := NewEnumFlag(&policy, "delete", "compact", "compact,delete")
+		if tt.caseInsensitive {
+			enum.CaseInsensitive()
+		}
+
+		err := enum.Set(tt.value)
+		if tt.expectedErr != "" {
+			if err == nil || err.Error() != tt.expectedErr {
+				t.Fatalf("%s: expected error %q but got: %v", tt.value, tt.expectedErr, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("%s: %v", tt.value, err)
+		}
+
+		if policy != tt.expected || enum.String() != tt.expected {
+			t.Fatalf("%s: expected value %s but got %s", tt.value, tt.expected, policy)
+		}
+	}
+}
+
+func TestEnumFlagVar(t *testing.T) {
+	policy := "Delete"
+	cmd := &cobra.Command{Use: "create"}
+	EnumFlagVar(cmd, NewEnumFlag(&policy, "delete", "compact").CaseInsensitive(), "policy", "", "The cleanup policy")
+
+	f := cmd.Flags().Lookup("policy")
+	if f.DefValue != "delete" || policy != "delete" {
+		t.Fatalf("expected the default value to be normalized but got %s", f.DefValue)
+	}
+
+	if expected := "The cleanup policy, one of: delete, compact"; f.Usage != expected {
+		t.Fatalf("expected usage %q but got %q", expected, f.Usage)
+	}
+
+	if err := cmd.ParseFlags([]string{"--policy=x"}); err == nil || !strings.Contains(err.Error(), `invalid argument "x" for "--policy" flag`) {
+		t.Fatalf("expected an invalid argument error but got: %v", err)
+	}
+
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), "invalid default value 'retain'") {
+			t.Fatalf("expected a panic for an invalid default value but got: %v", r)
+		}
+	}()
+
+	policy = "retain"
+	EnumFlagVar(&cobra.Command{Use: "update"}, NewEnumFlag(&policy, "delete", "compact"), "policy", "", "")
+}
@@ -0,0 +1,98 @@
+package bite
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// flagSourceAnnotation is the flag annotation that keeps where a flag's value came from, when not from the command line.
+const flagSourceAnnotation = "bite_flag_source"
+
+const (
+	flagSourceEnv = "env"
+)
+
+// EnvName returns the environment variable name of a flag, i.e "my-cli" and "header-fgcolor" to "MY_CLI_HEADER_FGCOLOR".
+func EnvName(prefix, flagName string) string {
+	name := strings.ToUpper(prefix + "_" + flagName)
+	return strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func (app *Application) envPrefix() string {
+	if app.EnvPrefix != "" {
+		return app.EnvPrefix
+	}
+
+	return strings.Split(app.Name, " ")[0]
+}
+
+func isEnvFallbackFlag(f *pflag.Flag) bool {
+	return f.Name != "help" && f.Name != "version"
+}
+
+// visitCommandFlags calls the "visitor" for the local and persistent flags of the "cmd" and its sub commands.
+func visitCommandFlags(cmd *cobra.Command, visitor func(*pflag.Flag)) {
+	cmd.LocalFlags().VisitAll(visitor)
+	for _, c := range cmd.Commands() {
+		visitCommandFlags(c, visitor)
+	}
+}
+
+// annotateEnvUsage appends the environment variable name of each flag to its usage, i.e "output format [$MYCLI_OUTPUT]".
+func annotateEnvUsage(cmd *cobra.Command, prefix string) {
+	visitCommandFlags(cmd, func(f *pflag.Flag) {
+		if !isEnvFallbackFlag(f) {
+			return
+		}
+
+		suffix := fmt.Sprintf(" [$%s]", EnvName(prefix, f.Name))
+		if !strings.HasSuffix(f.Usage, suffix) {
+			f.Usage += suffix
+		}
+	})
+}
+
+// applyEnvFlags sets the values of the flags that are not set by the command line from their environment variables, if any.
+func applyEnvFlags(cmd *cobra.Command, prefix string) error {
+	var err error
+	set := cmd.Flags()
+	set.VisitAll(func(f *pflag.Flag) {
+		if err != nil || f.Changed || !isEnvFallbackFlag(f) {
+			return
+		}
+
+		envName := EnvName(prefix, f.Name)
+		value, ok := os.LookupEnv(envName)
+		if !ok {
+			return
+		}
+
+		// not through `set.Set`, like the config values, they are not set by the command line, so they do not override file values,
+		// their source is kept instead, so they are still set for the config, the required flags and the flag groups, see `isFlagSet`.
+		if setErr := f.Value.Set(value); setErr != nil {
+			err = fmt.Errorf("invalid value '%s' of environment variable %s: %v", value, envName, setErr)
+			return
+		}
+
+		setFlagSource(f, flagSourceEnv)
+	})
+
+	return err
+}
+
+func setFlagSource(f *pflag.Flag, source string) {
+	if f.Annotations == nil {
+		f.Annotations = make(map[string][]string)
+	}
+
+	f.Annotations[flagSourceAnnotation] = []string{source}
+}
@@ -0,0 +1,92 @@
+package bite
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestEnvName(t *testing.T) {
+	if expected, got := "MY_CLI_HEADER_FGCOLOR", EnvName("my-cli", "header-fgcolor"); expected != got {
+		t.Fatalf("expected %s but got %s", expected, got)
+	}
+}
+
+func TestApplicationEnvFallback(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{"topic.yaml": "partitions: 3\n"})
+	path := filepath.Join(dir, "topic.yaml")
+
+	tests := []struct {
+		name               string
+		args               []string
+		expectedID         string
+		expectedName       string
+		expectedPartitions int
+		expectedErr        string
+	}{
+		{"env", nil, "42", "", 6, ""},
+		{"flag over env", []string{"--id=1", "--partitions=1"}, "1", "", 1, ""},
+		// env values are set by the user, so they count on flag groups.
+		{"group", []string{"--name=topic"}, "", "", 0, "can not be set together"},
+		{"group of flags", []string{"--id=1", "--name=topic"}, "", "", 0, "can not be set together"},
+		// env values do not override file values but flags do.
+		{"file over env", []string{"--file=" + path}, "42", "", 3, ""},
+		{"flag over file", []string{"--file=" + path, "--partitions=1"}, "42", "", 1, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("BITE_ENV_TEST_ID", "42")
+			t.Setenv("BITE_ENV_TEST_PARTITIONS", "6")
+
+			var (
+				topic struct {
+					ID         string `json:"id"`
+					Name       string `json:"name"`
+					Partitions int    `json:"partitions"`
+				}
+				ran bool
+			)
+
+			loadFile := FileBind(&topic)
+			cmd := CanLoadFiles(&cobra.Command{Use: "create", RunE: func(cmd *cobra.Command, args []string) error {
+				ran = true
+				return loadFile(cmd, args)
+			}})
+			cmd.Flags().StringVar(&topic.ID, "id", "", "")
+			cmd.Flags().StringVar(&topic.Name, "name", "", "")
+			cmd.Flags().IntVar(&topic.Partitions, "partitions", 0, "")
+			MutuallyExclusive(cmd, "id", "name")
+
+			app := &Application{EnvFallback: true, EnvPrefix: "bite_env_test"}
+			app.AddCommand(cmd)
+
+			err := executeTestApp(t, app, append([]string{"create"}, tt.args...)...)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error %q but got: %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !ran {
+				t.Fatal("expected the command to run")
+			}
+
+			if topic.ID != tt.expectedID || topic.Name != tt.expectedName || topic.Partitions != tt.expectedPartitions {
+				t.Fatalf("expected id %q, name %q and partitions %d but got %#v",
+					tt.expectedID, tt.expectedName, tt.expectedPartitions, topic)
+			}
+
+			if f := cmd.Flags().Lookup("id"); tt.expectedID == "42" && f.Changed {
+				t.Fatal("expected the env value to not mark the flag as changed")
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package bite
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// stdinFlagValue is the `@-` flag value, it reads the flag value from the standard input.
+const stdinFlagValue = "@-"
+
+// isPathFlag reports whether the "f" flag expects a path, marked by `cobra.MarkFlagFilename`, its value is never replaced by the file contents.
+func isPathFlag(f *pflag.Flag) bool {
+	_, ok := f.Annotations[cobra.BashCompFilenameExt]
+	return ok
+}
+
+// ApplyFileFlags replaces the values of the string and string slice flags of the "cmd" command that start with '@'
+// with the contents of the file, i.e `--query=@query.sql`, or of the standard input when `@-`, see `Application.FileFlags`.
+// The trailing new lines of the contents are removed. Flags that expect a path, see `cobra.MarkFlagFilename`, are skipped.
+func ApplyFileFlags(cmd *cobra.Command) error {
+	var (
+		err       error
+		stdinFlag string
+	)
+
+	read := func(name, value string) (string, error) {
+		if len(value) < 2 || value[0] != flagFilePrefix {
+			return value, nil
+		}
+
+		var (
+			b       []byte
+			readErr error
+		)
+
+		if value == stdinFlagValue {
+			if stdinFlag != "" {
+				return "", fmt.Errorf("flags %q and %q can not be both read from the standard input", stdinFlag, name)
+			}
+
+			stdinFlag = name
+			b, readErr = ioutil.ReadAll(os.Stdin)
+		} else {
+			b, readErr = TryReadFileContents(value)
+		}
+
+		if readErr != nil {
+			return "", fmt.Errorf("flag %s: %v", name, readErr)
+		}
+
+		return strings.TrimRight(string(b), "\r\n"), nil
+	}
+
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if err != nil || isPathFlag(f) {
+			return
+		}
+
+		if _, ok := f.Value.(*deprecatedFlagValue); ok {
+			return // shares the value of its replacement flag.
+		}
+
+		if secret, ok := f.Value.(*SecretFlag); ok {
+			var value string
+			if value, err = read(f.Name, secret.Value()); err == nil {
+				err = secret.Set(value)
+			}
+			return
+		}
+
+		if f.Value.Type() == "string" {
+			var value string
+			if value, err = read(f.Name, f.Value.String()); err == nil && value != f.Value.String() {
+				err = f.Value.Set(value)
+			}
+			return
+		}
+
+		if slice, ok := f.Value.(pflag.SliceValue); ok && (f.Value.Type() == "stringSlice" || f.Value.Type() == "stringArray") {
+			values := slice.GetSlice()
+			replaced := make([]string, len(values))
+			for i, value := range values {
+				if replaced[i], err = read(f.Name, value); err != nil {
+					return
+				}
+			}
+
+			err = slice.Replace(replaced)
+		}
+	})
+
+	return err
+}
@@ -0,0 +1,38 @@
+package bite
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestApplyFileFlags(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{"query.sql": "SELECT * FROM topic\n"})
+	query := filepath.Join(dir, "query.sql")
+
+	cmd := CanLoadFiles(&cobra.Command{Use: "run"})
+	cmd.Flags().String("query", "", "")
+	cmd.Flags().StringSlice("tag", nil, "")
+
+	if err := cmd.ParseFlags([]string{"--query=@" + query, "--tag=a,@" + query, "--file=@" + query}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ApplyFileFlags(cmd); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := cmd.Flags().GetString("query"); got != "SELECT * FROM topic" {
+		t.Fatalf("expected the query to be read from the file but got: %q", got)
+	}
+
+	if got, _ := cmd.Flags().GetStringSlice("tag"); !reflect.DeepEqual(got, []string{"a", "SELECT * FROM topic"}) {
+		t.Fatalf("expected the second tag to be read from the file but got: %v", got)
+	}
+
+	if got := GetFilesFlag(cmd); !reflect.DeepEqual(got, []string{"@" + query}) {
+		t.Fatalf("expected the path flag to be kept but got: %v", got)
+	}
+}
@@ -0,0 +1,178 @@
+package bite
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// the command annotations that keep the flag groups, each group is a space-separated list of flag names and groups are separated by ';'.
+const (
+	mutuallyExclusiveAnnotation = "bite_flag_groups_mutually_exclusive"
+	requiredTogetherAnnotation  = "bite_flag_groups_required_together"
+	oneRequiredAnnotation       = "bite_flag_groups_one_required"
+	// flagGroupsUsageAnnotation keeps the human readable lines of the flag groups, they are shown in the help output.
+	flagGroupsUsageAnnotation = "bite_flag_groups_usage"
+)
+
+// MutuallyExclusive declares that only one of the "flagNames" can be set on the "cmd" command,
+// i.e `MutuallyExclusive(cmd, "id", "name")`, it's checked before the command's `RunE`.
+func MutuallyExclusive(cmd *cobra.Command, flagNames ...string) {
+	addFlagGroup(cmd, mutuallyExclusiveAnnotation, flagNames, "mutually exclusive")
+}
+
+// RequiredTogether declares that if any of the "flagNames" is set on the "cmd" command then all of them must be set,
+// i.e `RequiredTogether(cmd, "username", "password")`, it's checked before the command's `RunE`.
+func RequiredTogether(cmd *cobra.Command, flagNames ...string) {
+	addFlagGroup(cmd, requiredTogetherAnnotation, flagNames, "required together")
+}
+
+// OneRequired declares that at least one of the "flagNames" must be set on the "cmd" command,
+// i.e `OneRequired(cmd, "id", "name")`, it's checked before the command's `RunE`.
+// Combine it with `MutuallyExclusive` for "exactly one of".
+func OneRequired(cmd *cobra.Command, flagNames ...string) {
+	addFlagGroup(cmd, oneRequiredAnnotation, flagNames, "at least one required")
+}
+
+func addFlagGroup(cmd *cobra.Command, annotation string, flagNames []string, description string) {
+	if len(flagNames) < 2 {
+		panic("a flag group requires at least two flags")
+	}
+
+	if cmd.Annotations == nil {
+		cmd.Annotations = make(map[string]string)
+	}
+
+	if _, registered := cmd.Annotations[flagGroupsUsageAnnotation]; !registered {
+		// the current usage function, the command's own, the parent's or cobra's default one which renders the (inherited) usage template.
+		usage := cmd.UsageFunc()
+		cmd.SetUsageFunc(func(c *cobra.Command) error {
+			return flagGroupsUsage(cmd, c, usage)
+		})
+
+		prependPreRunE(cmd, CheckFlagGroups)
+	}
+
+	group := strings.Join(flagNames, " ")
+	if groups := cmd.Annotations[annotation]; groups != "" {
+		group = groups + ";" + group
+	}
+	cmd.Annotations[annotation] = group
+
+	dashed := make([]string, len(flagNames))
+	for i, name := range flagNames {
+		dashed[i] = "--" + name
+	}
+	cmd.Annotations[flagGroupsUsageAnnotation] += fmt.Sprintf("  %s: %s\n", description, strings.Join(dashed, ", "))
+}
+
+// flagGroupsUsage prints the usage of the "c" command through the "usage" function,
+// followed by the flag groups of the "cmd" command if "c" is the "cmd" and not one of its sub commands which inherit its usage function.
+func flagGroupsUsage(cmd, c *cobra.Command, usage func(*cobra.Command) error) error {
+	if err := usage(c); err != nil {
+		return err
+	}
+
+	if c != cmd {
+		return nil
+	}
+
+	_, err := fmt.Fprintf(c.OutOrStderr(), "\nFlag Groups:\n%s", c.Annotations[flagGroupsUsageAnnotation])
+	return err
+}
+
+func flagGroups(cmd *cobra.Command, annotation string) (groups [][]string) {
+	value := cmd.Annotations[annotation]
+	if value == "" {
+		return nil
+	}
+
+	for _, group := range strings.Split(value, ";") {
+		groups = append(groups, strings.Fields(group))
+	}
+
+	return
+}
+
+// CheckFlagGroups checks the flag groups of the "cmd" command based on the flags that are set by the user,
+// through the command line, an environment variable or the config file,
+// see `MutuallyExclusive`, `RequiredTogether` and `OneRequired`.
+func CheckFlagGroups(cmd *cobra.Command) error {
+	set := cmd.Flags()
+
+	for _, group := range flagGroups(cmd, mutuallyExclusiveAnnotation) {
+		var changed []string
+		for _, name := range group {
+			if isFlagSet(set, name) {
+				changed = append(changed, strconv.Quote(name))
+			}
+		}
+
+		if len(changed) > 1 {
+			// flags "id" and "name" can not be set together
+			return fmt.Errorf("flags %s can not be set together", joinFlagNames(changed, "and"))
+		}
+	}
+
+	for _, group := range flagGroups(cmd, requiredTogetherAnnotation) {
+		var changed, missing []string
+		for _, name := range group {
+			if isFlagSet(set, name) {
+				changed = append(changed, strconv.Quote(name))
+			} else {
+				missing = append(missing, strconv.Quote(name))
+			}
+		}
+
+		if len(changed) > 0 && len(missing) > 0 {
+			if len(missing) == 1 {
+				// required flag "password" not set, flags "username" and "password" must be set together
+				return fmt.Errorf("required flag %s not set, flags %s must be set together", missing[0], joinFlagNames(quoteAll(group), "and"))
+			}
+
+			return fmt.Errorf("required flags %s not set, flags %s must be set together", joinFlagNames(missing, "and"), joinFlagNames(quoteAll(group), "and"))
+		}
+	}
+
+	for _, group := range flagGroups(cmd, oneRequiredAnnotation) {
+		found := false
+		for _, name := range group {
+			if isFlagSet(set, name) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			// one of the flags "id" or "name" must be set
+			err := fmt.Errorf("one of the flags %s must be set", joinFlagNames(quoteAll(group), "or"))
+			if cmd.Example != "" {
+				err = fmt.Errorf("%s\nexample:\n\t%s", err, cmd.Example)
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func quoteAll(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = strconv.Quote(name)
+	}
+
+	return quoted
+}
+
+// joinFlagNames joins the "names" like `"a", "b" and "c"`, the "conjunction" is the last separator, i.e "and" or "or".
+func joinFlagNames(names []string, conjunction string) string {
+	if n := len(names); n > 1 {
+		return fmt.Sprintf("%s %s %s", strings.Join(names[0:n-1], ", "), conjunction, names[n-1])
+	}
+
+	return strings.Join(names, "")
+}
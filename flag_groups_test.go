@@ -0,0 +1,197 @@
+package bite
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func newTestFlagGroupsCommand() *cobra.Command {
+	cmd := &cobra.Command{Use: "delete", Example: "delete --id=1", RunE: func(*cobra.Command, []string) error { return nil }}
+	for _, name := range []string{"id", "name", "username", "password", "token"} {
+		cmd.Flags().String(name, "", "")
+	}
+
+	MutuallyExclusive(cmd, "id", "name")
+	OneRequired(cmd, "id", "name")
+	RequiredTogether(cmd, "username", "password", "token")
+	return cmd
+}
+
+func TestCheckFlagGroups(t *testing.T) {
+	tests := []struct {
+		args        []string
+		expectedErr string
+	}{
+		{[]string{"--id=1"}, ""},
+		{[]string{"--name=topic", "--username=u", "--password=p", "--token=t"}, ""},
+		{[]string{"--id=1", "--name=topic"}, `flags "id" and "name" can not be set together`},
+		{nil, "one of the flags \"id\" or \"name\" must be set\nexample:\n\tdelete --id=1"},
+		{[]string{"--id=1", "--username=u", "--token=t"}, `required flag "password" not set, flags "username", "password" and "token" must be set together`},
+		{[]string{"--id=1", "--password=p"}, `required flags "username" and "token" not set, flags "username", "password" and "token" must be set together`},
+	}
+
+	for _, tt := range tests {
+		cmd := newTestFlagGroupsCommand()
+		cmd.SetOutput(new(bytes.Buffer))
+		cmd.SetArgs(tt.args)
+
+		err := cmd.Execute()
+		if tt.expectedErr == "" {
+			if err != nil {
+				t.Fatalf("%v: %v", tt.args, err)
+			}
+			continue
+		}
+
+		if err == nil || err.Error() != tt.expectedErr {
+			t.Fatalf("%v: expected error:\n%s\nbut got:\n%v", tt.args, tt.expectedErr, err)
+		}
+	}
+}
+
+func TestApplicationFlagGroupSources(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{"config.yaml": "password: p\ntoken: t\n"})
+
+	tests := []struct {
+		name        string
+		env         map[string]string
+		args        []string
+		expectedErr string
+	}{
+		{"env, config and flag", map[string]string{"BITE_GROUPS_ID": "1"}, []string{"--username=u"}, ""},
+		{"env and flag", map[string]string{"BITE_GROUPS_ID": "1"}, []string{"--name=topic", "--username=u"}, `flags "id" and "name" can not be set together`},
+		{"config only", nil, []string{"--name=topic"}, `required flag "username" not set, flags "username", "password" and "token" must be set together`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			app := &Application{
+				EnvFallback: true,
+				EnvPrefix:   "bite_groups",
+				ConfigFile:  true,
+				ConfigPath:  filepath.Join(dir, "config.yaml"),
+			}
+			app.AddCommand(newTestFlagGroupsCommand())
+
+			err := executeTestApp(t, app, append([]string{"delete"}, tt.args...)...)
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			if err == nil || err.Error() != tt.expectedErr {
+				t.Fatalf("expected error:\n%s\nbut got:\n%v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestCheckFlagGroupsPreRun(t *testing.T) {
+	tests := []struct {
+		args           []string
+		expectedPreRun bool
+	}{
+		{[]string{"--id=1", "--name=topic"}, false},
+		{[]string{"--id=1"}, true},
+	}
+
+	for _, tt := range tests {
+		preRan := false
+		cmd := &cobra.Command{
+			Use:    "delete",
+			PreRun: func(*cobra.Command, []string) { preRan = true },
+			RunE:   func(*cobra.Command, []string) error { return nil },
+		}
+		cmd.Flags().String("id", "", "")
+		cmd.Flags().String("name", "", "")
+		MutuallyExclusive(cmd, "id", "name")
+
+		cmd.SetOutput(new(bytes.Buffer))
+		cmd.SetArgs(tt.args)
+
+		if err := cmd.Execute(); (err == nil) != tt.expectedPreRun || preRan != tt.expectedPreRun {
+			t.Fatalf("%v: expected the PreRun to run after a successful flag groups check only but got: %v", tt.args, err)
+		}
+	}
+}
+
+func TestFlagGroupsUsage(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "mycli"}
+	cmd := newTestFlagGroupsCommand()
+	rootCmd.AddCommand(cmd)
+	// set after the groups, the usage template is not frozen on registration.
+	rootCmd.SetUsageTemplate("custom usage of {{.Name}}\n")
+
+	out := new(bytes.Buffer)
+	cmd.SetOutput(out)
+	if err := cmd.Usage(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "custom usage of delete\n\nFlag Groups:\n" +
+		"  mutually exclusive: --id, --name\n" +
+		"  at least one required: --id, --name\n" +
+		"  required together: --username, --password, --token\n"
+	if got := out.String(); got != expected {
+		t.Fatalf("expected usage:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	if !strings.Contains(cmd.UsageString(), "Flag Groups:") {
+		t.Fatal("expected the help's usage to contain the flag groups")
+	}
+
+	// sub commands inherit the usage function but not the flag groups.
+	subCmd := &cobra.Command{Use: "topic"}
+	cmd.AddCommand(subCmd)
+	if got := subCmd.UsageString(); got != "custom usage of topic\n" {
+		t.Fatalf("expected the usage of the sub command without flag groups but got:\n%s", got)
+	}
+}
+
+func TestFlagGroupsCustomUsage(t *testing.T) {
+	cmd := &cobra.Command{Use: "delete"}
+	cmd.Flags().String("id", "", "")
+	cmd.Flags().String("name", "", "")
+	cmd.SetUsageFunc(func(c *cobra.Command) error {
+		_, err := c.OutOrStderr().Write([]byte("custom usage func of " + c.Name() + "\n"))
+		return err
+	})
+	MutuallyExclusive(cmd, "id", "name")
+
+	expected := "custom usage func of delete\n\nFlag Groups:\n  mutually exclusive: --id, --name\n"
+	if got := cmd.UsageString(); got != expected {
+		t.Fatalf("expected usage:\n%s\nbut got:\n%s", expected, got)
+	}
+}
+
+func TestJoinFlagNames(t *testing.T) {
+	tests := []struct {
+		names    []string
+		expected string
+	}{
+		{nil, ""},
+		{[]string{"a"}, `"a"`},
+		{[]string{"a", "b"}, `"a" and "b"`},
+		{[]string{"a", "b", "c"}, `"a", "b" and "c"`},
+	}
+
+	for _, tt := range tests {
+		if got := joinFlagNames(quoteAll(tt.names), "and"); got != tt.expected {
+			t.Fatalf("%v: expected %s but got %s", tt.names, tt.expected, got)
+		}
+	}
+
+	if got := flagGroups(newTestFlagGroupsCommand(), requiredTogetherAnnotation); len(got) != 1 || strings.Join(got[0], " ") != "username password token" {
+		t.Fatalf("unexpected required together groups: %v", got)
+	}
+}
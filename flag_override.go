@@ -0,0 +1,167 @@
+package bite
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// IgnoreFlagsBind makes the file loader to keep the file values as they are,
+// flags that are explicitly set are not applied on top of them, see `FileLoader.IgnoreFlags`.
+func IgnoreFlagsBind() func(*FileLoader) {
+	return func(fl *FileLoader) {
+		fl.IgnoreFlags()
+	}
+}
+
+// IgnoreFlags disables the flag overrides, by default the flags that are explicitly set by the user
+// and can be mapped to a field of the loaded value override the file's values, see `ApplyFlagOverrides`.
+func (fl *FileLoader) IgnoreFlags() *FileLoader {
+	fl.ignoreFlags = true
+	return fl
+}
+
+// fileLoaderFlagKeys are the flags that bite registers to load the files, they are never mapped to fields, see `changedFlagValues`.
+var fileLoaderFlagKeys = []string{
+	filesFlagKey, printMergedFlagKey, recursiveFlagKey, valuesFlagKey, templateSetFlagKey, setFlagKey, setStringFlagKey, setJSONFlagKey,
+}
+
+// changedFlagValues returns the values of the command's own flags that are explicitly set by the user, keyed by their normalized names.
+// Inherited flags, i.e the root's `--output`, are not included as they may match a field of the loaded value by accident,
+// neither the file loader's flags, i.e `--file`, as they are inputs of the loading and not values.
+// It should be called before loading a file, as flags may be bound to the same value that a file is decoded to.
+func changedFlagValues(cmd *cobra.Command) map[string]reflect.Value {
+	values := make(map[string]reflect.Value)
+	set := cmd.LocalFlags()
+	set.VisitAll(func(f *pflag.Flag) { // not `Visit`, the local flag set does not keep the changed ones.
+		if !f.Changed || containsString(fileLoaderFlagKeys, f.Name) {
+			return
+		}
+
+		if v, err := getFlagValue(set, f); err == nil && v != nil {
+			values[normalizeFlagName(f.Name)] = reflect.ValueOf(v)
+		}
+	})
+
+	return values
+}
+
+// ApplyFlagOverrides sets the values of the explicitly set, not inherited, flags of the "cmd" command
+// to the "outPtr"'s struct fields, so the precedence is: flags that are set by the user, then the file's values, then the flags' defaults.
+//
+// A flag is mapped to a field by its `flag` struct tag, i.e `flag:"partitions"`,
+// otherwise by its json or yaml tag name or by its field name, case-insensitive and with dashes ignored.
+// Fields of nested structs are mapped by their full path, like the flags of `BindFlags`,
+// i.e the `Name` of a `Tenant` struct field to the `--tenant-name` flag and not to the `--name` one.
+// Each flag is set to the first matching field only.
+func ApplyFlagOverrides(cmd *cobra.Command, outPtr interface{}) {
+	applyFlagOverrides(outPtr, changedFlagValues(cmd))
+}
+
+func applyFlagOverrides(outPtr interface{}, values map[string]reflect.Value) {
+	if len(values) == 0 {
+		return
+	}
+
+	v := indirectValue(reflect.ValueOf(outPtr))
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	applyFlagOverridesTo(v, "", values, make(map[string]bool))
+}
+
+// applyFlagOverridesTo sets the "values" to the fields of the "v" struct, the fields are mapped by the "prefix" and their names,
+// the "applied" keeps the values that are already set, so a value is not set to more than one field.
+func applyFlagOverridesTo(v reflect.Value, prefix string, values map[string]reflect.Value, applied map[string]bool) {
+	typ := v.Type()
+	for i, n := 0, typ.NumField(); i < n; i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" && !field.Anonymous { // unexported.
+			continue
+		}
+
+		fieldValue := v.Field(i)
+
+		name := prefix + fieldFlagName(field)
+		if field.Anonymous && !hasFieldNameTag(field) { // embedded fields are promoted.
+			name = prefix
+		}
+
+		if flagValue, ok := values[name]; ok && !applied[name] && fieldValue.CanSet() {
+			applied[name] = setFieldValue(fieldValue, flagValue)
+			continue
+		}
+
+		if indirectType(field.Type).Kind() == reflect.Struct {
+			if fieldValue.Kind() == reflect.Ptr {
+				if fieldValue.IsNil() {
+					continue
+				}
+				fieldValue = fieldValue.Elem()
+			}
+
+			applyFlagOverridesTo(fieldValue, name, values, applied)
+		}
+	}
+}
+
+var fieldNameTags = []string{"flag", "json", "yaml"}
+
+// fieldFlagName returns the flag name that a struct field is mapped to.
+func fieldFlagName(field reflect.StructField) string {
+	for _, tagName := range fieldNameTags {
+		if tag := field.Tag.Get(tagName); tag != "" && tag != "-" {
+			if name := strings.Split(tag, ",")[0]; name != "" {
+				return normalizeFlagName(name)
+			}
+		}
+	}
+
+	return normalizeFlagName(field.Name)
+}
+
+func hasFieldNameTag(field reflect.StructField) bool {
+	for _, tagName := range fieldNameTags {
+		if tag := field.Tag.Get(tagName); tag != "" && tag != "-" && strings.Split(tag, ",")[0] != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func normalizeFlagName(name string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", "_", "", ".", "").Replace(name))
+}
+
+func setFieldValue(field, value reflect.Value) bool {
+	if field.Kind() == reflect.Ptr && value.Kind() != reflect.Ptr {
+		elem := reflect.New(field.Type().Elem())
+		if !setFieldValue(elem.Elem(), value) {
+			return false
+		}
+
+		field.Set(elem)
+		return true
+	}
+
+	if value.Type().AssignableTo(field.Type()) {
+		field.Set(value)
+		return true
+	}
+
+	// do not let reflect convert numbers to strings(runes) and the opposite.
+	if (field.Kind() == reflect.String) != (value.Kind() == reflect.String) {
+		return false
+	}
+
+	if value.Type().ConvertibleTo(field.Type()) {
+		field.Set(value.Convert(field.Type()))
+		return true
+	}
+
+	return false
+}
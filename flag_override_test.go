@@ -0,0 +1,104 @@
+package bite
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestFileBindFlagOverrides(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{"topic.yaml": "name: topic\npartitions: 3\nreplication: 2\n"})
+	path := filepath.Join(dir, "topic.yaml")
+
+	tests := []struct {
+		name                string
+		args                []string
+		expectedName        string
+		expectedPartitions  int
+		expectedReplication int
+	}{
+		{"file", []string{"create", path}, "topic", 3, 2},
+		{"flag over file", []string{"create", path, "--partitions=12"}, "topic", 12, 2},
+		{"zero flag over file", []string{"create", path, "--replication=0"}, "topic", 3, 0},
+		// the parent's --name is not a flag of the "create" command.
+		{"inherited flag under file", []string{"--name=parent", "create", path}, "topic", 3, 2},
+	}
+
+	for _, tt := range tests {
+		var topic struct {
+			Name        string `yaml:"name"`
+			Partitions  int    `yaml:"partitions"`
+			Replication int    `yaml:"replication"`
+		}
+
+		rootCmd := &cobra.Command{Use: "topics", TraverseChildren: true}
+		rootCmd.PersistentFlags().String("name", "", "")
+
+		cmd := &cobra.Command{Use: "create", RunE: FileBind(&topic)}
+		cmd.Flags().IntVar(&topic.Partitions, "partitions", 1, "")
+		cmd.Flags().IntVar(&topic.Replication, "replication", 1, "")
+		rootCmd.AddCommand(cmd)
+
+		rootCmd.SetOutput(ioutil.Discard)
+		rootCmd.SetArgs(tt.args)
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("[%s] %v", tt.name, err)
+		}
+
+		if topic.Name != tt.expectedName || topic.Partitions != tt.expectedPartitions || topic.Replication != tt.expectedReplication {
+			t.Fatalf("[%s] expected name %q, partitions %d and replication %d but got %#v",
+				tt.name, tt.expectedName, tt.expectedPartitions, tt.expectedReplication, topic)
+		}
+	}
+}
+
+func TestFileBindNestedFlagOverrides(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{"topic.yaml": "name: topic\nfile: [topic.yaml]\ntenant:\n  name: tenant\n"})
+	path := filepath.Join(dir, "topic.yaml")
+
+	tests := []struct {
+		name               string
+		args               []string
+		expectedName       string
+		expectedTenantName string
+	}{
+		{"file", nil, "topic", "tenant"},
+		// the --name is mapped to the top-level field only.
+		{"flag over file", []string{"--name=other"}, "other", "tenant"},
+		{"nested flag over file", []string{"--tenant-name=other"}, "topic", "other"},
+		{"flags over file", []string{"--name=other", "--tenant-name=another"}, "other", "another"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var topic struct {
+				Name   string   `yaml:"name"`
+				File   []string `yaml:"file"`
+				Tenant struct {
+					Name string `yaml:"name"`
+				} `yaml:"tenant"`
+			}
+
+			cmd := CanLoadFiles(&cobra.Command{Use: "create", RunE: FileBind(&topic)})
+			cmd.Flags().String("name", "", "")
+			cmd.Flags().String("tenant-name", "", "")
+
+			cmd.SetOutput(ioutil.Discard)
+			cmd.SetArgs(append([]string{"--file=" + path}, tt.args...))
+			if err := cmd.Execute(); err != nil {
+				t.Fatal(err)
+			}
+
+			if topic.Name != tt.expectedName || topic.Tenant.Name != tt.expectedTenantName {
+				t.Fatalf("expected name %q and tenant name %q but got %#v", tt.expectedName, tt.expectedTenantName, topic)
+			}
+
+			// the --file flag of bite is not a value of the loaded file.
+			if len(topic.File) != 1 || topic.File[0] != "topic.yaml" {
+				t.Fatalf("expected file [topic.yaml] but got %v", topic.File)
+			}
+		})
+	}
+}
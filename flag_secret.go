@@ -0,0 +1,145 @@
+package bite
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+)
+
+// secretMask is shown instead of a secret's value, i.e in help defaults and flag dumps.
+const secretMask = "******"
+
+// secretFileFlagSuffix is the suffix of the flag that reads a secret from a file, i.e `--password-file`.
+const secretFileFlagSuffix = "-file"
+
+// SecretFlag is a string `pflag.Value` for passwords and tokens, its value is never shown, `String` returns a mask instead.
+// The value can be set by the flag itself, i.e `--password`, by a file, i.e `--password-file`, by an environment variable, see `Env`,
+// or, if none of them is set and the input is a terminal, by a no-echo prompt.
+// Use `SecretFlagVar` to register it to a command.
+type SecretFlag struct {
+	value  *string
+	env    string
+	prompt string
+}
+
+// NewSecretFlag returns a new `SecretFlag` which sets its value to the "ptr", its current value is the default one.
+func NewSecretFlag(ptr *string) *SecretFlag {
+	return &SecretFlag{value: ptr}
+}
+
+// Env sets the environment variable that the secret is read from, when it's not set by the command line.
+func (s *SecretFlag) Env(name string) *SecretFlag {
+	s.env = name
+	return s
+}
+
+// Prompt sets the label of the terminal prompt, defaults to "Enter <flag name>: ".
+func (s *SecretFlag) Prompt(label string) *SecretFlag {
+	s.prompt = label
+	return s
+}
+
+// Value returns the actual value of the secret.
+func (s *SecretFlag) Value() string {
+	if s.value == nil {
+		return ""
+	}
+
+	return *s.value
+}
+
+// String returns a mask if the secret has a value, the actual value is never shown.
+func (s *SecretFlag) String() string {
+	if s.Value() == "" {
+		return ""
+	}
+
+	return secretMask
+}
+
+func (s *SecretFlag) Set(v string) error {
+	*s.value = v
+	return nil
+}
+
+// Type returns "string", so `GetString` of the flag set can be used, note that `GetString` returns the mask, use `Value` instead.
+func (s *SecretFlag) Type() string {
+	return "string"
+}
+
+// IsSecretFlag reports whether the "f" flag is a `SecretFlag`, its value should never be shown.
+func IsSecretFlag(f *pflag.Flag) bool {
+	_, ok := f.Value.(*SecretFlag)
+	return ok
+}
+
+// SecretFlagVar registers the "secret" flag and its `--<name>-file` flag to the "cmd" command,
+// the secret is resolved, from the file, the environment variable or the prompt, before the command's `RunE`.
+func SecretFlagVar(cmd *cobra.Command, secret *SecretFlag, name, shorthand, usage string) *SecretFlag {
+	cmd.Flags().VarP(secret, name, shorthand, usage)
+	fileFlagName := name + secretFileFlagSuffix
+	cmd.Flags().String(fileFlagName, "", fmt.Sprintf("Read the --%s value from a file", name))
+	cmd.MarkFlagFilename(fileFlagName)
+
+	prependPreRunE(cmd, func(c *cobra.Command) error {
+		return secret.resolve(c, name, fileFlagName)
+	})
+
+	return secret
+}
+
+func (s *SecretFlag) resolve(cmd *cobra.Command, name, fileFlagName string) error {
+	set := cmd.Flags()
+
+	if set.Changed(name) {
+		if set.Changed(fileFlagName) {
+			return fmt.Errorf("flags %q and %q can not be set together", name, fileFlagName)
+		}
+
+		return nil
+	}
+
+	if path, _ := set.GetString(fileFlagName); path != "" {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		// files usually end with a new line which is not part of the secret.
+		return s.Set(strings.TrimRight(string(b), "\r\n"))
+	}
+
+	if s.env != "" {
+		if value, ok := os.LookupEnv(s.env); ok {
+			return s.Set(value)
+		}
+	}
+
+	if s.Value() != "" {
+		return nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil
+	}
+
+	label := s.prompt
+	if label == "" {
+		label = fmt.Sprintf("Enter %s: ", name)
+	}
+
+	fmt.Fprint(cmd.ErrOrStderr(), label)
+	b, err := term.ReadPassword(fd)
+	fmt.Fprintln(cmd.ErrOrStderr())
+	if err != nil {
+		return err
+	}
+
+	return s.Set(string(b))
+}
@@ -0,0 +1,70 @@
+package bite
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestSecretFlag(t *testing.T) {
+	f, err := ioutil.TempFile("", "bite-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("s3cr3t\n")
+	f.Close()
+
+	var password string
+	cmd := &cobra.Command{Use: "login", RunE: func(*cobra.Command, []string) error { return nil }}
+	secret := SecretFlagVar(cmd, NewSecretFlag(&password), "password", "", "the password")
+
+	cmd.SetArgs([]string{"--password-file", f.Name()})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	if password != "s3cr3t" {
+		t.Fatalf("expected password to be read from the file but got: %q", password)
+	}
+
+	if got := cmd.Flags().Lookup("password").Value.String(); got != secretMask {
+		t.Fatalf("expected the flag value to be masked but got: %q", got)
+	}
+
+	if got, _ := GetFlagValue(cmd, "password"); got != secret.Value() {
+		t.Fatalf("expected the actual value but got: %q", got)
+	}
+
+	cmd.SetArgs([]string{"--password", "a", "--password-file", f.Name()})
+	if err = cmd.Execute(); err == nil || !strings.Contains(err.Error(), "can not be set together") {
+		t.Fatalf("expected error for both flags but got: %v", err)
+	}
+}
+
+func TestSecretFlagPreRun(t *testing.T) {
+	var (
+		password string
+		got      string
+	)
+
+	cmd := &cobra.Command{
+		Use:    "login",
+		PreRun: func(*cobra.Command, []string) { got = password },
+		RunE:   func(*cobra.Command, []string) error { return nil },
+	}
+	SecretFlagVar(cmd, NewSecretFlag(&password).Env("BITE_SECRET_TEST_PASSWORD"), "password", "", "the password")
+
+	t.Setenv("BITE_SECRET_TEST_PASSWORD", "s3cr3t")
+	cmd.SetArgs(nil)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got != "s3cr3t" {
+		t.Fatalf("expected the PreRun to run with the resolved secret but got: %q", got)
+	}
+}
@@ -1,10 +1,14 @@
 package bite
 
 import (
+	"encoding"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -15,7 +19,7 @@ type FlagPair map[string]interface{}
 type Flags = pflag.FlagSet
 
 // CheckRequiredFlags function can be used to manually check for required flags, when the command does not specify a required flag (mostly because of file loading feature).
-func CheckRequiredFlags(cmd *cobra.Command, nameValuePairs FlagPair) (err error) {
+func CheckRequiredFlags(cmd *cobra.Command, nameValuePairs FlagPair) error {
 	if nameValuePairs == nil {
 		return nil
 	}
@@ -32,18 +36,23 @@ func CheckRequiredFlags(cmd *cobra.Command, nameValuePairs FlagPair) (err error)
 		}
 	}
 
-	if n := len(emptyFlags); n > 0 {
+	return requiredFlagsError(cmd, emptyFlags, len(nameValuePairs))
+}
+
+// requiredFlagsError returns the error of the "missing" (quoted) required flags, if any,
+// if all the "total" required flags are missing then the command's example is shown as well.
+func requiredFlagsError(cmd *cobra.Command, missing []string, total int) (err error) {
+	if n := len(missing); n > 0 {
 		if n == 1 {
 			// required flag "flag 1" not set
-			err = fmt.Errorf("required flag %s not set", emptyFlags[0])
+			err = fmt.Errorf("required flag %s not set", missing[0])
 		} else {
 			// required flags "flag 1" and "flag 2" not set
 			// required flags "flag 1", "flag 2" and "flag 3" not set
-			err = fmt.Errorf("required flags %s and %s not set",
-				strings.Join(emptyFlags[0:n-1], ", "), emptyFlags[n-1])
+			err = fmt.Errorf("required flags %s not set", joinFlagNames(missing, "and"))
 		}
 
-		if len(nameValuePairs) == n {
+		if total == n {
 			// if all required flags are not passed, then show an example in the end.
 			err = fmt.Errorf("%s\nexample:\n\t%s", err, cmd.Example)
 		}
@@ -58,124 +67,370 @@ func RequireFlags(nameValuePairsFunc func() FlagPair) CobraRunner {
 	}
 }
 
-// CountRegisteredFlags returns the length of the registered flags (except help), some of them may not be used "now" at all.
-func CountRegisteredFlags(set *pflag.FlagSet) (n int) {
-	// formal instead of actual, no hidden, it may include help flag.
-	set.VisitAll(func(*pflag.Flag) { n++ })
-	return
+// CheckRequiredFlagNames checks if the "flagNames" are set by the user, through the command line, an environment variable or the config file,
+// based on their state and not their values, so a flag explicitly set to its zero value, i.e `--partitions=0` or `--enabled=false`, is accepted.
+// A flag is set by the loaded file as well, if the file contains a key with the same (normalized) name,
+// nested keys by their full path, i.e `tenant: {name: acme}` sets the `--tenant-name` flag of `BindFlags` but not the `--name`.
+// Therefore it should run after the file loading, i.e `Join(FileBind(&opts), RequireFlagNames("name", "partitions"), run)`.
+func CheckRequiredFlagNames(cmd *cobra.Command, flagNames ...string) error {
+	keys := getFileKeys(cmd)
+
+	var missing []string
+	for _, name := range flagNames {
+		if isFlagSet(cmd.Flags(), name) || keys.has(normalizeFlagName(name)) {
+			continue
+		}
+
+		missing = append(missing, strconv.Quote(name))
+	}
+
+	return requiredFlagsError(cmd, missing, len(flagNames))
+}
+
+// RequireFlagNames returns a runner which checks the required "flagNames", see `CheckRequiredFlagNames`.
+func RequireFlagNames(flagNames ...string) CobraRunner {
+	return func(cmd *cobra.Command, args []string) error {
+		return CheckRequiredFlagNames(cmd, flagNames...)
+	}
+}
+
+// fileKeys keeps the (normalized) keys of the last loaded file(s) of a command, see `CheckRequiredFlagNames`.
+// They are collected on the first check, so the loaded document is not decoded again when nothing is checked.
+type fileKeys struct {
+	once sync.Once
+	doc  func() interface{}
+	keys map[string]bool
 }
 
-func getFlagValue(name string, set *pflag.FlagSet) (flagValue reflect.Value) {
-	flag := set.Lookup(name)
-	flagName := flag.Name
-
-	switch flag.Value.Type() {
-	case "string":
-		vv, _ := set.GetString(flagName)
-		// don't be lazy, don't try something like 'var flagValue interface{}; ...end... reflect.ValueOf(flagValue)`.
-		flagValue = reflect.ValueOf(vv)
-	case "bool":
-		vv, _ := set.GetBool(flagName)
-		flagValue = reflect.ValueOf(vv)
-
-	case "int", "count":
-		vv, _ := set.GetInt(flagName)
-		flagValue = reflect.ValueOf(vv)
-	case "int32":
-		vv, _ := set.GetInt32(flagName)
-		flagValue = reflect.ValueOf(vv)
-	case "int64":
-		vv, _ := set.GetInt64(flagName)
-		flagValue = reflect.ValueOf(vv)
-	case "float32":
-		vv, _ := set.GetFloat32(flagName)
-		flagValue = reflect.ValueOf(vv)
-	case "float64":
-		vv, _ := set.GetFloat64(flagName)
-		flagValue = reflect.ValueOf(vv)
-
-	case "uint8":
-		vv, _ := set.GetUint8(flagName)
-		flagValue = reflect.ValueOf(vv)
-	case "uint16":
-		vv, _ := set.GetUint16(flagName)
-		flagValue = reflect.ValueOf(vv)
-	case "uint32":
-		vv, _ := set.GetUint32(flagName)
-		flagValue = reflect.ValueOf(vv)
-	case "uint64":
-		vv, _ := set.GetUint64(flagName)
-		flagValue = reflect.ValueOf(vv)
-
-	case "stringSlice":
-		vv, _ := set.GetStringSlice(flagName)
-		flagValue = reflect.ValueOf(vv)
-	case "intSlice":
-		vv, _ := set.GetIntSlice(flagName)
-		flagValue = reflect.ValueOf(vv)
-	case "uintSlice":
-		vv, _ := set.GetUintSlice(flagName)
-		flagValue = reflect.ValueOf(vv)
-	case "boolSlice":
-		vv, _ := set.GetBoolSlice(flagName)
-		flagValue = reflect.ValueOf(vv)
-
-	case "ip":
-		vv, _ := set.GetIP(flagName)
-		flagValue = reflect.ValueOf(vv)
-	case "ipMask":
-		vv, _ := set.GetIPv4Mask(flagName)
-		flagValue = reflect.ValueOf(vv)
-	case "ipNet":
-		vv, _ := set.GetIPNet(flagName)
-		flagValue = reflect.ValueOf(vv)
+func (k *fileKeys) has(key string) bool {
+	if k == nil {
+		return false
 	}
 
+	k.once.Do(func() {
+		k.keys = make(map[string]bool)
+		collectDocumentKeys(k.doc(), "", k.keys)
+	})
+
+	return k.keys[key]
+}
+
+var (
+	loadedFileKeys   = make(map[*cobra.Command]*fileKeys)
+	loadedFileKeysMu sync.Mutex
+)
+
+func getFileKeys(cmd *cobra.Command) *fileKeys {
+	loadedFileKeysMu.Lock()
+	keys := loadedFileKeys[cmd]
+	loadedFileKeysMu.Unlock()
+
+	return keys
+}
+
+// recordFileFlags keeps the document of the loaded file(s) of the "cmd", its keys are flag names, see `CheckRequiredFlagNames`.
+// The "doc" returns the decoded document, it's called once and only if needed.
+func recordFileFlags(cmd *cobra.Command, doc func() interface{}) {
+	loadedFileKeysMu.Lock()
+	loadedFileKeys[cmd] = &fileKeys{doc: doc}
+	loadedFileKeysMu.Unlock()
+}
+
+func resetFileFlags(cmd *cobra.Command) {
+	loadedFileKeysMu.Lock()
+	delete(loadedFileKeys, cmd)
+	loadedFileKeysMu.Unlock()
+}
+
+// collectDocumentKeys collects the keys of the "doc", nested keys are collected with their parent's prefix only,
+// i.e `tenant: {name: acme}` to "tenant" and "tenantname" which matches the `--tenant-name` flag of `BindFlags`.
+func collectDocumentKeys(doc interface{}, prefix string, keys map[string]bool) {
+	m, ok := doc.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for key, value := range m {
+		key = prefix + normalizeFlagName(key)
+		keys[key] = true
+		collectDocumentKeys(value, key, keys)
+	}
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CountRegisteredFlags returns the length of the registered flags (except help), some of them may not be used "now" at all.
+func CountRegisteredFlags(set *pflag.FlagSet) (n int) {
+	// formal instead of actual, no hidden, it may include help flag.
+	set.VisitAll(func(*pflag.Flag) { n++ })
 	return
 }
 
-// Supported custom types underline are: strings, ints and booleans only.
+// FlagVar is a `pflag.Value` for any custom type, use `NewFlagVar` to create one from a pointer.
+//
+// Supported underline types are: strings, booleans, all int, uint and float widths, time.Duration,
+// time.Time (see `WithLayout`), slices (comma-separated and repeated), maps (comma-separated and repeated key=value pairs)
+// and any type that implements the `encoding.TextUnmarshaler`. Unsupported types fail on `Set`.
 type FlagVar struct {
-	value reflect.Value
+	value  reflect.Value
+	layout string
+	// the first `Set` replaces the default value of slices and maps, the next ones append to it.
+	changed *bool
 }
 
 func NewFlagVar(v interface{}) *FlagVar {
-	return &FlagVar{reflect.ValueOf(v)}
+	return &FlagVar{value: reflect.ValueOf(v), layout: time.RFC3339, changed: new(bool)}
+}
+
+// WithLayout sets the layout of a time.Time value, defaults to time.RFC3339.
+func (f *FlagVar) WithLayout(layout string) *FlagVar {
+	if layout != "" {
+		f.layout = layout
+	}
+
+	return f
 }
 
 func (f FlagVar) String() string {
-	return f.value.Elem().String()
+	if f.value.Kind() != reflect.Ptr || f.value.IsNil() {
+		return ""
+	}
+
+	return f.formatValue(f.value.Elem())
 }
 
-func (f FlagVar) Set(v string) error {
-	typ := f.value.Elem().Kind()
-	switch typ {
+func (f FlagVar) formatValue(v reflect.Value) string {
+	if v.Type() == timeTyp {
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(f.layout)
+	}
+
+	if v.Type() == durationTyp {
+		return time.Duration(v.Int()).String()
+	}
+
+	if v.CanInterface() {
+		if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+			if b, err := m.MarshalText(); err == nil {
+				return string(b)
+			}
+		}
+	}
+
+	switch v.Kind() {
 	case reflect.String:
-		f.value.Elem().SetString(v)
-		break
-	case reflect.Int:
-		intValue, err := strconv.Atoi(v)
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits())
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = f.formatValue(v.Index(i))
+		}
+		return "[" + strings.Join(items, ",") + "]"
+	case reflect.Map:
+		pairs := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			pairs = append(pairs, f.formatValue(key)+"="+f.formatValue(v.MapIndex(key)))
+		}
+		sort.Strings(pairs)
+		return "[" + strings.Join(pairs, ",") + "]"
+	case reflect.Ptr:
+		if v.IsNil() {
+			return ""
+		}
+		return f.formatValue(v.Elem())
+	default:
+		return fmt.Sprintf("%v", v.Interface())
+	}
+}
+
+func (f FlagVar) Set(v string) error {
+	if f.value.Kind() != reflect.Ptr || f.value.IsNil() {
+		return fmt.Errorf("flag value is not a pointer")
+	}
+
+	elem := f.value.Elem()
+
+	if elem.Type() == timeTyp {
+		t, err := time.Parse(f.layout, v)
 		if err != nil {
 			return err
 		}
 
-		f.value.Elem().SetInt(int64(intValue))
-		break
-	case reflect.Bool:
-		boolValue, err := strconv.ParseBool(v)
-		if err != nil {
+		elem.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch elem.Kind() {
+	case reflect.Slice, reflect.Map:
+		if _, ok := f.value.Interface().(encoding.TextUnmarshaler); ok {
+			break // i.e net.IP.
+		}
+
+		parsed := reflect.New(elem.Type()).Elem()
+		if err := setValueFromString(parsed, v); err != nil {
 			return err
 		}
 
-		f.value.Elem().SetBool(boolValue)
-		break
+		if f.changed == nil || !*f.changed {
+			elem.Set(parsed)
+		} else if elem.Kind() == reflect.Slice {
+			elem.Set(reflect.AppendSlice(elem, parsed))
+		} else {
+			if elem.IsNil() {
+				elem.Set(reflect.MakeMap(elem.Type()))
+			}
+
+			for _, key := range parsed.MapKeys() {
+				elem.SetMapIndex(key, parsed.MapIndex(key))
+			}
+		}
+
+		if f.changed != nil {
+			*f.changed = true
+		}
+
+		return nil
 	}
 
-	return nil
+	return setValueFromString(elem, v)
 }
 
 func (f FlagVar) Type() string {
-	return f.value.Elem().Kind().String() // reflect/type.go#605
+	if !f.value.IsValid() {
+		return ""
+	}
+
+	typ := indirectType(f.value.Type())
+	switch {
+	case typ == timeTyp:
+		return "time"
+	case typ == durationTyp:
+		return "duration"
+	case typ.Kind() == reflect.Slice && typ.Elem().Kind() != reflect.Uint8:
+		return typ.Elem().Kind().String() + "Slice" // same as pflag's, i.e "stringSlice".
+	case typ.Kind() == reflect.Map && typ.Key().Kind() == reflect.String && typ.Elem().Kind() == reflect.String:
+		return "stringToString"
+	}
+
+	return typ.Kind().String() // reflect/type.go#605
+}
+
+var (
+	timeTyp            = reflect.TypeOf(time.Time{})
+	durationTyp        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerTyp = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// setValueFromString parses the "s" based on the type of the "v" and sets the result to the "v",
+// slices are comma-separated values and maps are comma-separated key=value pairs.
+func setValueFromString(v reflect.Value, s string) error {
+	if v.Type() == durationTyp {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerTyp) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		var parts []string
+		if s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]"); s != "" {
+			parts = strings.Split(s, ",")
+		}
+
+		slice := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setValueFromString(slice.Index(i), strings.TrimSpace(part)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	case reflect.Map:
+		m := reflect.MakeMap(v.Type())
+		if s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]"); s != "" {
+			for _, pair := range strings.Split(s, ",") {
+				idx := strings.IndexByte(pair, '=')
+				if idx == -1 {
+					return fmt.Errorf("'%s' is not a key=value pair", pair)
+				}
+
+				key := reflect.New(v.Type().Key()).Elem()
+				if err := setValueFromString(key, strings.TrimSpace(pair[:idx])); err != nil {
+					return err
+				}
+
+				value := reflect.New(v.Type().Elem()).Elem()
+				if err := setValueFromString(value, pair[idx+1:]); err != nil {
+					return err
+				}
+
+				m.SetMapIndex(key, value)
+			}
+		}
+		v.Set(m)
+	case reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		if err := setValueFromString(elem.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(elem)
+	default:
+		return fmt.Errorf("unsupported flag type %s", v.Type())
+	}
+
+	return nil
 }
 
 func whichColor(v string, base int) (intValue int) {
@@ -0,0 +1,175 @@
+package bite
+
+import (
+	"net"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type testCleanupPolicy string
+
+func TestFlagVar(t *testing.T) {
+	var (
+		policy    testCleanupPolicy
+		ratio     float32
+		retention time.Duration
+		since     time.Time
+		tags      = []string{"default"}
+		ports     []uint16
+		configs   map[string]string
+		ip        net.IP
+		unknown   struct{}
+	)
+
+	set := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	set.Var(NewFlagVar(&policy), "policy", "")
+	set.Var(NewFlagVar(&ratio), "ratio", "")
+	set.Var(NewFlagVar(&retention), "retention", "")
+	set.Var(NewFlagVar(&since).WithLayout("2006-01-02"), "since", "")
+	set.Var(NewFlagVar(&tags), "tag", "")
+	set.Var(NewFlagVar(&ports), "port", "")
+	set.Var(NewFlagVar(&configs), "config", "")
+	set.Var(NewFlagVar(&ip), "ip", "")
+	set.Var(NewFlagVar(&unknown), "unknown", "")
+
+	err := set.Parse([]string{
+		"--policy=compact", "--ratio=0.5", "--retention=1h30m", "--since=2018-05-01",
+		"--tag=a,b", "--tag=c", "--port=9092", "--port=9093,9094",
+		"--config=cleanup.policy=delete", "--config", "retention.ms=1000,segment.ms=10",
+		"--ip=127.0.0.1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if policy != "compact" || ratio != 0.5 || retention != 90*time.Minute {
+		t.Fatalf("unexpected values: %v, %v, %v", policy, ratio, retention)
+	}
+
+	if expected := time.Date(2018, 5, 1, 0, 0, 0, 0, time.UTC); !since.Equal(expected) {
+		t.Fatalf("expected time %s but got %s", expected, since)
+	}
+
+	if expected := []string{"a", "b", "c"}; !reflect.DeepEqual(tags, expected) {
+		t.Fatalf("expected tags %v but got %v", expected, tags)
+	}
+
+	if expected := []uint16{9092, 9093, 9094}; !reflect.DeepEqual(ports, expected) {
+		t.Fatalf("expected ports %v but got %v", expected, ports)
+	}
+
+	if expected := map[string]string{"cleanup.policy": "delete", "retention.ms": "1000", "segment.ms": "10"}; !reflect.DeepEqual(configs, expected) {
+		t.Fatalf("expected configs %v but got %v", expected, configs)
+	}
+
+	if !ip.Equal(net.ParseIP("127.0.0.1")) {
+		t.Fatalf("expected ip 127.0.0.1 but got %s", ip)
+	}
+
+	if got := set.Lookup("tag").Value.Type(); got != "stringSlice" {
+		t.Fatalf("expected type stringSlice but got %s", got)
+	}
+
+	if got, _ := set.GetStringSlice("tag"); !reflect.DeepEqual(got, tags) {
+		t.Fatalf("expected tags %v through GetStringSlice but got %v", tags, got)
+	}
+
+	if err = set.Set("unknown", "value"); err == nil {
+		t.Fatalf("expected error for unsupported type")
+	}
+}
+
+func TestCheckRequiredFlagNames(t *testing.T) {
+	var (
+		name       string
+		partitions int
+	)
+
+	cmd := &cobra.Command{Use: "create", Example: "create --name=topic --partitions=1"}
+	cmd.Flags().StringVar(&name, "name", "", "")
+	cmd.Flags().IntVar(&partitions, "partitions", 1, "")
+
+	if err := cmd.Flags().Parse([]string{"--partitions=0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := CheckRequiredFlagNames(cmd, "name", "partitions")
+	if err == nil || err.Error() != `required flag "name" not set` {
+		t.Fatalf("expected missing name error but got: %v", err)
+	}
+
+	recordFileFlags(cmd, func() interface{} {
+		return map[string]interface{}{"tenant": map[string]interface{}{"name": "acme"}}
+	})
+	if err = CheckRequiredFlagNames(cmd, "name", "partitions"); err == nil || err.Error() != `required flag "name" not set` {
+		t.Fatalf("expected a nested key to not set a top-level flag but got: %v", err)
+	}
+
+	if err = CheckRequiredFlagNames(cmd, "tenant-name", "partitions"); err != nil {
+		t.Fatalf("expected a nested key to set the flag of its full path but got: %v", err)
+	}
+
+	recordFileFlags(cmd, func() interface{} { return map[string]interface{}{"name": "topic"} })
+	if err = CheckRequiredFlagNames(cmd, "name", "partitions"); err != nil {
+		t.Fatalf("expected flags to be satisfied by the command line and the file but got: %v", err)
+	}
+
+	resetFileFlags(cmd)
+	cmd.Flags().Lookup("partitions").Changed = false
+	expected := "required flags \"name\" and \"partitions\" not set\nexample:\n\tcreate --name=topic --partitions=1"
+	if err = CheckRequiredFlagNames(cmd, "name", "partitions"); err == nil || err.Error() != expected {
+		t.Fatalf("expected error:\n%s\nbut got:\n%v", expected, err)
+	}
+}
+
+func TestApplicationRequiredFlagSources(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{"config.yaml": "partitions: 3\n"})
+
+	tests := []struct {
+		name        string
+		env         map[string]string
+		args        []string
+		expectedErr string
+	}{
+		{"env and config", map[string]string{"BITE_REQUIRED_NAME": "topic"}, nil, ""},
+		{"flag and config", nil, []string{"--name=topic"}, ""},
+		{"config only", nil, nil, `required flag "name" not set`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cmd := &cobra.Command{Use: "create", RunE: RequireFlagNames("name", "partitions")}
+			cmd.Flags().String("name", "", "")
+			cmd.Flags().Int("partitions", 1, "")
+
+			app := &Application{
+				EnvFallback: true,
+				EnvPrefix:   "bite_required",
+				ConfigFile:  true,
+				ConfigPath:  filepath.Join(dir, "config.yaml"),
+			}
+			app.AddCommand(cmd)
+
+			err := executeTestApp(t, app, append([]string{"create"}, tt.args...)...)
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			if err == nil || err.Error() != tt.expectedErr {
+				t.Fatalf("expected error %q but got: %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package bite
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// the sources of a flag's value, see `DumpFlags`, the env and config ones are kept by the `flagSourceAnnotation`.
+const (
+	flagSourceDefault = "default"
+	flagSourceFlag    = "flag"
+)
+
+var pflagPkgPath = reflect.TypeOf(pflag.FlagSet{}).PkgPath()
+
+// LookupFlag returns the "name" flag of the "cmd" command, or of its parents' persistent flags, or nil if not registered.
+func LookupFlag(cmd *cobra.Command, name string) (*pflag.Flag, *pflag.FlagSet) {
+	if f := cmd.Flags().Lookup(name); f != nil {
+		return f, cmd.Flags()
+	}
+
+	for c := cmd; c != nil; c = c.Parent() {
+		if f := c.PersistentFlags().Lookup(name); f != nil {
+			return f, c.PersistentFlags()
+		}
+	}
+
+	return nil, nil
+}
+
+// GetFlagValue returns the typed value of the "name" flag of the "cmd" command, or of its parents' persistent flags,
+// i.e a `time.Duration` for a duration flag or a `map[string]string` for a string to string one.
+// Custom values return their underline value if they are a `FlagVar` or implement the `flag.Getter`, otherwise their string form.
+// The actual value of a `SecretFlag` is returned, use `DumpFlags` for a masked one.
+func GetFlagValue(cmd *cobra.Command, name string) (interface{}, error) {
+	f, set := LookupFlag(cmd, name)
+	if f == nil {
+		return nil, fmt.Errorf("unknown flag %q", name)
+	}
+
+	return getFlagValue(set, f)
+}
+
+type flagGetter interface {
+	Get() interface{}
+}
+
+func getFlagValue(set *pflag.FlagSet, f *pflag.Flag) (interface{}, error) {
+	switch v := f.Value.(type) {
+	case *deprecatedFlagValue:
+		return getFlagValue(v.set, v.set.Lookup(v.name))
+	case *SecretFlag:
+		return v.Value(), nil
+	case *EnumFlag:
+		return v.String(), nil
+	case *FlagVar:
+		if v.value.Kind() != reflect.Ptr || v.value.IsNil() {
+			return nil, nil
+		}
+		return v.value.Elem().Interface(), nil
+	case flagGetter:
+		return v.Get(), nil
+	}
+
+	if typ := reflect.TypeOf(f.Value); typ.Kind() != reflect.Ptr || typ.Elem().PkgPath() != pflagPkgPath {
+		return f.Value.String(), nil
+	}
+
+	name := f.Name
+
+	switch f.Value.Type() {
+	case "string":
+		return set.GetString(name)
+	case "bool":
+		return set.GetBool(name)
+	case "count":
+		return set.GetCount(name)
+
+	case "int":
+		return set.GetInt(name)
+	case "int8":
+		return set.GetInt8(name)
+	case "int16":
+		return set.GetInt16(name)
+	case "int32":
+		return set.GetInt32(name)
+	case "int64":
+		return set.GetInt64(name)
+	case "uint":
+		return set.GetUint(name)
+	case "uint8":
+		return set.GetUint8(name)
+	case "uint16":
+		return set.GetUint16(name)
+	case "uint32":
+		return set.GetUint32(name)
+	case "uint64":
+		return set.GetUint64(name)
+	case "float32":
+		return set.GetFloat32(name)
+	case "float64":
+		return set.GetFloat64(name)
+	case "duration":
+		return set.GetDuration(name)
+
+	case "stringSlice":
+		return set.GetStringSlice(name)
+	case "stringArray":
+		return set.GetStringArray(name)
+	case "boolSlice":
+		return set.GetBoolSlice(name)
+	case "intSlice":
+		return set.GetIntSlice(name)
+	case "int32Slice":
+		return set.GetInt32Slice(name)
+	case "int64Slice":
+		return set.GetInt64Slice(name)
+	case "uintSlice":
+		return set.GetUintSlice(name)
+	case "float32Slice":
+		return set.GetFloat32Slice(name)
+	case "float64Slice":
+		return set.GetFloat64Slice(name)
+	case "durationSlice":
+		return set.GetDurationSlice(name)
+
+	case "stringToString":
+		return set.GetStringToString(name)
+	case "stringToInt":
+		return set.GetStringToInt(name)
+	case "stringToInt64":
+		return set.GetStringToInt64(name)
+
+	case "bytesHex":
+		return set.GetBytesHex(name)
+	case "bytesBase64":
+		return set.GetBytesBase64(name)
+
+	case "ip":
+		return set.GetIP(name)
+	case "ipSlice":
+		return set.GetIPSlice(name)
+	case "ipMask":
+		return set.GetIPv4Mask(name)
+	case "ipNet":
+		return set.GetIPNet(name)
+	}
+
+	return f.Value.String(), nil
+}
+
+// FlagValue is the effective value of a flag and where it came from, see `DumpFlags`.
+type FlagValue struct {
+	Name   string      `json:"name" yaml:"Name" header:"Name"`
+	Value  interface{} `json:"value" yaml:"Value" header:"Value"`
+	Source string      `json:"source" yaml:"Source" header:"Source"`
+}
+
+// DumpFlags returns the effective values of all the flags of the "cmd" command, including its parents' persistent flags, sorted by name.
+// The source of each value is one of: "default", "flag", "env" or "config". The values of the `SecretFlag`s are masked.
+func DumpFlags(cmd *cobra.Command) ([]FlagValue, error) {
+	var (
+		values []FlagValue
+		err    error
+		seen   = make(map[string]bool)
+	)
+
+	visit := func(set *pflag.FlagSet) {
+		set.VisitAll(func(f *pflag.Flag) {
+			if err != nil || seen[f.Name] || f.Name == "help" {
+				return
+			}
+			seen[f.Name] = true
+
+			if _, ok := f.Value.(*deprecatedFlagValue); ok {
+				return // its replacement is dumped instead.
+			}
+
+			var value interface{}
+			if IsSecretFlag(f) {
+				value = f.Value.String()
+			} else if value, err = getFlagValue(set, f); err != nil {
+				return
+			}
+
+			values = append(values, FlagValue{Name: f.Name, Value: value, Source: flagSource(f)})
+		})
+	}
+
+	visit(cmd.Flags())
+	for c := cmd; c != nil; c = c.Parent() {
+		visit(c.PersistentFlags())
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].Name < values[j].Name
+	})
+
+	return values, nil
+}
+
+// isFlagSet reports whether the flag "name" is set by the command line, an environment variable or the config file.
+func isFlagSet(set *pflag.FlagSet, name string) bool {
+	f := set.Lookup(name)
+	return f != nil && flagSource(f) != flagSourceDefault
+}
+
+func flagSource(f *pflag.Flag) string {
+	if sources := f.Annotations[flagSourceAnnotation]; len(sources) > 0 {
+		return sources[0]
+	}
+
+	if f.Changed {
+		return flagSourceFlag
+	}
+
+	return flagSourceDefault
+}
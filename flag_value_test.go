@@ -0,0 +1,60 @@
+package bite
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestDumpFlags(t *testing.T) {
+	var password string
+
+	root := &cobra.Command{Use: "mycli"}
+	root.PersistentFlags().String("output", "table", "")
+	cmd := &cobra.Command{Use: "create", Run: func(*cobra.Command, []string) {}}
+	root.AddCommand(cmd)
+
+	cmd.Flags().Duration("timeout", time.Second, "")
+	cmd.Flags().StringToString("config", nil, "")
+	cmd.Flags().BytesHex("key", nil, "")
+	cmd.Flags().Int("partitions", 1, "")
+	SecretFlagVar(cmd, NewSecretFlag(&password), "password", "", "")
+
+	if err := cmd.ParseFlags([]string{"--timeout=1m", "--config=a=b", "--key=ff", "--password=s3cr3t"}); err != nil {
+		t.Fatal(err)
+	}
+	ApplyConfig(cmd, map[string]interface{}{"partitions": 3})
+
+	if v, err := GetFlagValue(cmd, "timeout"); err != nil || v != time.Minute {
+		t.Fatalf("expected a duration but got: %v (%v)", v, err)
+	}
+
+	if v, err := GetFlagValue(cmd, "output"); err != nil || v != "table" {
+		t.Fatalf("expected the parent's persistent flag value but got: %v (%v)", v, err)
+	}
+
+	if _, err := GetFlagValue(cmd, "unknown"); err == nil {
+		t.Fatal("expected an error for an unknown flag")
+	}
+
+	values, err := DumpFlags(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []FlagValue{
+		{Name: "config", Value: map[string]string{"a": "b"}, Source: "flag"},
+		{Name: "key", Value: []byte{0xff}, Source: "flag"},
+		{Name: "output", Value: "table", Source: "default"},
+		{Name: "partitions", Value: 3, Source: "config"},
+		{Name: "password", Value: secretMask, Source: "flag"},
+		{Name: "password-file", Value: "", Source: "default"},
+		{Name: "timeout", Value: time.Minute, Source: "flag"},
+	}
+
+	if !reflect.DeepEqual(values, expected) {
+		t.Fatalf("expected:\n%#v\nbut got:\n%#v", expected, values)
+	}
+}
@@ -0,0 +1,25 @@
+module github.com/lensesio/bite
+
+go 1.18
+
+require (
+	github.com/BurntSushi/toml v0.3.1
+	github.com/briandowns/spinner v1.23.2
+	github.com/jmespath/go-jmespath v0.4.0
+	github.com/lensesio/tableprinter v0.0.0-20201125135848-89e81fc956e7
+	github.com/spf13/cobra v1.1.1
+	github.com/spf13/pflag v1.0.5
+	golang.org/x/term v0.1.0
+	gopkg.in/yaml.v2 v2.4.0
+)
+
+require (
+	github.com/dustin/go-humanize v1.0.0 // indirect
+	github.com/fatih/color v1.7.0 // indirect
+	github.com/inconshreveable/mousetrap v1.1.0 // indirect
+	github.com/kataras/tablewriter v0.0.0-20180708051242-e063d29b7c23 // indirect
+	github.com/mattn/go-colorable v0.1.2 // indirect
+	github.com/mattn/go-isatty v0.0.8 // indirect
+	github.com/mattn/go-runewidth v0.0.9 // indirect
+	golang.org/x/sys v0.0.0-20220412211240-33da011f77ad // indirect
+)
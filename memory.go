@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -11,7 +12,29 @@ import (
 // Memory describes a temporary storage for each application, useful to store different kind of custom values.
 type Memory struct {
 	tmp map[uint8]interface{} // why not string and uint8 as key? With uint8(0-255) we set a kind of limit of the elements stored without any further complications.
-	mu  sync.RWMutex
+	// namespaces keeps the values of each namespace, see `Namespace`, for libraries that need their own key space.
+	namespaces map[string]map[interface{}]interface{}
+	// expires keeps the expiration time of the values that are set with a TTL and loaders the lazy loaders, see `SetWithTTL` and `SetLoader`.
+	expires map[uint8]time.Time
+	loaders map[uint8]memoryLoader
+	loading map[uint8]*memoryLoad
+	mu      sync.RWMutex
+
+	// persisted keeps the keys that are kept on disk, see `Persist`.
+	persisted map[uint8]persistedKey
+	// order keeps the insertion order of the values and the finalizers, see `Close`.
+	order      []memoryRef
+	tracked    map[memoryRef]bool
+	finalizers []func() error
+	// providers keeps the named providers, see `Provide`, their values are kept in their own namespace.
+	providers map[string]Provider
+
+	// Clock is used to expire the values, defaults to the system's clock, useful for tests.
+	Clock Clock
+	// Store keeps the persisted values on disk between invocations, see `Persist` and `Application.PersistentMemory`.
+	Store *DiskStore
+	// storeErr is the reason of a missing `Store`, i.e an unknown cache directory, it's returned when a persisted key is used.
+	storeErr error
 }
 
 // m.Set(MyKey, MyValue{}) == existed and replaced, safe for concurrent access.
@@ -24,6 +47,9 @@ func (m *Memory) Set(key uint8, value interface{}) (replacement bool) {
 
 	m.mu.Lock()
 	m.tmp[key] = value
+	m.track(memoryRef{key: key})
+	delete(m.expires, key)
+	delete(m.loaders, key)
 	m.mu.Unlock()
 
 	return
@@ -41,6 +67,9 @@ func (m *Memory) SetOnce(key uint8, value interface{}) bool {
 
 	m.mu.Lock()
 	m.tmp[key] = value
+	m.track(memoryRef{key: key})
+	delete(m.expires, key)
+	delete(m.loaders, key)
 	m.mu.Unlock()
 
 	return true
@@ -109,23 +138,49 @@ func (m *Memory) SetOnceFunc(key uint8, receiverFunc interface{}) error {
 	return nil
 }
 
-// m.Unset(MyKey) == removed, safe for concurrent access.
-func (m *Memory) Unset(key uint8) (removed bool) {
-	if len(m.tmp) == 0 {
-		return
+// setOnceFunc stores the value of the "fn" if the key is not there already, see `Key.SetOnceFunc`.
+// Concurrent calls of the same key call a single "fn".
+func (m *Memory) setOnceFunc(key uint8, fn func() (interface{}, error)) error {
+	if m.tmp == nil {
+		return nil
 	}
 
-	if m.Has(key) {
-		m.mu.Lock()
-		delete(m.tmp, key)
+	m.mu.Lock()
+	m.evict(key)
+	if _, exists := m.tmp[key]; exists {
 		m.mu.Unlock()
-		removed = true
+		return nil
 	}
 
+	_, err := m.loadOnce(key, fn, func(value interface{}) {
+		if _, exists := m.tmp[key]; !exists { // not set meanwhile, i.e by a `Set`.
+			m.tmp[key] = value
+			m.track(memoryRef{key: key})
+			delete(m.expires, key)
+			delete(m.loaders, key)
+		}
+	})
+
+	return err
+}
+
+// m.Unset(MyKey) == removed, safe for concurrent access.
+func (m *Memory) Unset(key uint8) (removed bool) {
+	m.mu.Lock()
+	_, stored := m.tmp[key]
+	_, lazy := m.loaders[key]
+	if removed = stored || lazy; removed {
+		m.untrack(memoryRef{key: key})
+		delete(m.tmp, key)
+		delete(m.expires, key)
+		delete(m.loaders, key)
+	}
+	m.mu.Unlock()
+
 	return
 }
 
-// m.Has(MyKey) == exists, safe for concurrent access.
+// m.Has(MyKey) == exists and not expired, safe for concurrent access.
 func (m *Memory) Has(key uint8) bool {
 	if len(m.tmp) == 0 {
 		return false
@@ -133,21 +188,22 @@ func (m *Memory) Has(key uint8) bool {
 
 	m.mu.RLock()
 	_, exists := m.tmp[key]
+	expired := exists && m.expired(key)
 	m.mu.RUnlock()
 
-	return exists
+	if expired {
+		m.mu.Lock()
+		m.evict(key)
+		m.mu.Unlock()
+	}
+
+	return exists && !expired
 }
 
 // value, found := m.Get(MyKey), safe for concurrent access.
+// Expired values are not found, unless they have a loader, see `SetLoader`, which recomputes them.
 func (m *Memory) Get(key uint8) (value interface{}, found bool) {
-	if len(m.tmp) == 0 {
-		return
-	}
-
-	m.mu.Lock()
-	value, found = m.tmp[key]
-	m.mu.Unlock()
-
+	value, found, _ = m.load(key)
 	return
 }
 
@@ -164,7 +220,7 @@ func (m *Memory) MustGet(key uint8) interface{} {
 	return v
 }
 
-// GetAll returns a clone of all the stored values, safe for concurrent access.
+// GetAll returns a clone of all the stored, not expired, values, safe for concurrent access.
 func (m *Memory) GetAll() map[uint8]interface{} {
 	if len(m.tmp) == 0 {
 		return make(map[uint8]interface{})
@@ -172,12 +228,27 @@ func (m *Memory) GetAll() map[uint8]interface{} {
 
 	clone := make(map[uint8]interface{}, len(m.tmp))
 
+	var expired []uint8
+
 	m.mu.RLock()
 	for key, value := range m.tmp {
+		if m.expired(key) {
+			expired = append(expired, key)
+			continue
+		}
+
 		clone[key] = value
 	}
 	m.mu.RUnlock()
 
+	if len(expired) > 0 {
+		m.mu.Lock()
+		for _, key := range expired {
+			m.evict(key)
+		}
+		m.mu.Unlock()
+	}
+
 	return clone
 }
 
@@ -217,24 +288,47 @@ func (m *Memory) Visit(key uint8, visitorFunc interface{}) bool {
 	return true
 }
 
-// Clear removes all the stored elements and returns the total length of the elements removed, safe for concurrent access.
+// Clear removes all the stored elements, including the namespaced ones, and returns the total length of the elements removed, safe for concurrent access.
 func (m *Memory) Clear() int {
-	n := len(m.tmp)
-	if n == 0 {
-		return 0
-	}
-
 	m.mu.Lock()
+	n := len(m.tmp)
 	for key := range m.tmp {
 		delete(m.tmp, key)
 	}
+
+	for name, values := range m.namespaces {
+		n += len(values)
+		delete(m.namespaces, name)
+	}
+
+	for key := range m.expires {
+		delete(m.expires, key)
+	}
+
+	for key := range m.loaders {
+		delete(m.loaders, key)
+	}
+
+	// keep the order of the finalizers only.
+	order := m.order
+	m.order, m.tracked = nil, nil
+	for _, ref := range order {
+		if ref.finalizer > 0 {
+			m.track(ref)
+		}
+	}
 	m.mu.Unlock()
 
 	return n
 }
 
 func makeMemory() *Memory {
-	return &Memory{tmp: make(map[uint8]interface{})}
+	return &Memory{
+		tmp:        make(map[uint8]interface{}),
+		namespaces: make(map[string]map[interface{}]interface{}),
+		expires:    make(map[uint8]time.Time),
+		loaders:    make(map[uint8]memoryLoader),
+	}
 }
 
 func GetMemory(cmd *cobra.Command) *Memory {
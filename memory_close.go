@@ -0,0 +1,207 @@
+package bite
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+)
+
+// memoryRef is a reference to a stored value, or to a finalizer, see `Memory.Close`.
+type memoryRef struct {
+	namespace  string
+	namespaced bool
+	key        interface{}
+	finalizer  int // 1-based index of the finalizers, zero for values.
+}
+
+// track keeps the insertion order of the "ref", the caller should hold the lock.
+func (m *Memory) track(ref memoryRef) {
+	if m.tracked[ref] {
+		return
+	}
+
+	if m.tracked == nil {
+		m.tracked = make(map[memoryRef]bool)
+	}
+
+	m.tracked[ref] = true
+	m.order = append(m.order, ref)
+}
+
+// untrack removes the "ref" from the insertion order, so its next insertion is the last one, the caller should hold the lock.
+func (m *Memory) untrack(ref memoryRef) {
+	if !m.tracked[ref] {
+		return
+	}
+
+	delete(m.tracked, ref)
+	for i, tracked := range m.order {
+		if tracked == ref {
+			m.order = append(m.order[:i], m.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// OnClose registers a finalizer which is called by `Close`, in reverse insertion order along with the stored values,
+// i.e `mem.OnClose(func() error { return os.RemoveAll(tmpDir) })`, safe for concurrent access.
+func (m *Memory) OnClose(finalizer func() error) {
+	m.mu.Lock()
+	m.finalizers = append(m.finalizers, finalizer)
+	m.track(memoryRef{finalizer: len(m.finalizers)})
+	m.mu.Unlock()
+}
+
+// CloseError is the error of `Memory.Close`, it keeps the errors of all the values that failed to close.
+type CloseError struct {
+	Errors []error
+}
+
+func (e *CloseError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+
+	return fmt.Sprintf("%d errors occurred while closing: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// closer returns the close function of the "value", if it's an `io.Closer` or it has a `Close()` method, otherwise nil.
+func closer(value interface{}) func() error {
+	switch v := value.(type) {
+	case io.Closer:
+		return v.Close
+	case interface{ Close() }:
+		return func() error {
+			v.Close()
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// Close closes the stored values that are `io.Closer`s, or have a `Close()` method, and calls the finalizers, see `OnClose`,
+// in reverse insertion order, i.e HTTP clients and Kafka consumers. The closed values and the resolved provider values, see `Provide`, are removed.
+// All of them are closed, even if some fail, the failures are returned as a `CloseError`.
+// It's called after the command's execution, even if the command failed.
+func (m *Memory) Close() error {
+	var closers []func() error
+
+	m.mu.Lock()
+	for i := len(m.order) - 1; i >= 0; i-- {
+		ref := m.order[i]
+		if ref.finalizer > 0 {
+			closers = append(closers, m.finalizers[ref.finalizer-1])
+			continue
+		}
+
+		var (
+			value  interface{}
+			stored bool
+		)
+
+		if ref.namespaced {
+			value, stored = m.namespaces[ref.namespace][ref.key]
+		} else {
+			value, stored = m.tmp[ref.key.(uint8)]
+		}
+
+		closeFunc := closer(value)
+		if !stored || closeFunc == nil {
+			continue
+		}
+
+		closers = append(closers, closeFunc)
+		if ref.namespaced {
+			delete(m.namespaces[ref.namespace], ref.key)
+		} else {
+			delete(m.tmp, ref.key.(uint8))
+		}
+	}
+
+	delete(m.namespaces, providersNamespace)
+	m.order, m.tracked, m.finalizers = nil, nil, nil
+	m.mu.Unlock()
+
+	// not under lock, a value may access the memory on close.
+	var errs []error
+	for _, closeFunc := range closers {
+		if err := closeFunc(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return &CloseError{Errors: errs}
+	}
+
+	return nil
+}
+
+// closeMemory closes the memory after the command's execution, the command's error, if any, takes precedence
+// and the close errors are printed to the standard error instead.
+func (app *Application) closeMemory(rootCmd *cobra.Command, err error) error {
+	if app.Memory == nil {
+		return err
+	}
+
+	closeErr := app.Memory.Close()
+	if err == nil {
+		return closeErr
+	}
+
+	if closeErr != nil {
+		fmt.Fprintln(rootCmd.ErrOrStderr(), closeErr)
+	}
+
+	return err
+}
+
+// closeMemoryOnInterrupt closes the memory and calls the `OnInterrupt` when the process is interrupted, until the returned "stop" is called.
+// It does nothing if the `OnInterrupt` is nil.
+func (app *Application) closeMemoryOnInterrupt() (stop func()) {
+	if app.OnInterrupt == nil || app.Memory == nil {
+		return func() {}
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	wait := app.handleInterrupt(interrupt)
+
+	return func() {
+		signal.Stop(interrupt)
+		wait()
+	}
+}
+
+// handleInterrupt closes the memory and calls the `OnInterrupt` on the first signal of the "interrupt",
+// until the returned "stop" is called, which waits for the `OnInterrupt` to return, if called.
+// The memory is closed once, as the values closed by the interrupt are removed, the close after the execution skips them.
+func (app *Application) handleInterrupt(interrupt <-chan os.Signal) (stop func()) {
+	done, finished := make(chan struct{}), make(chan struct{})
+
+	go func() {
+		defer close(finished)
+
+		select {
+		case sig := <-interrupt:
+			app.OnInterrupt(sig, app.Memory.Close())
+		case <-done:
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+	}
+}
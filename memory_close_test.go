@@ -0,0 +1,150 @@
+package bite
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+type testCloser struct {
+	name   string
+	closed *[]string
+	err    error
+}
+
+func (c testCloser) Close() error {
+	*c.closed = append(*c.closed, c.name)
+	return c.err
+}
+
+func TestMemoryClose(t *testing.T) {
+	var closed []string
+	errConsumer := errors.New("consumer failed")
+	errTmp := errors.New("tmp failed")
+
+	m := makeMemory()
+	m.Set(1, testCloser{name: "http", closed: &closed})
+	m.Set(2, "not closable")
+	m.OnClose(func() error {
+		closed = append(closed, "tmp")
+		return errTmp
+	})
+	m.Namespace("kafka").Set("consumer", testCloser{name: "consumer", closed: &closed, err: errConsumer})
+	m.Set(3, testCloser{name: "unset", closed: &closed})
+	m.Unset(3)
+	m.Set(1, testCloser{name: "http", closed: &closed}) // replacement keeps its position.
+
+	err := m.Close()
+	if expected := []string{"consumer", "tmp", "http"}; !reflect.DeepEqual(closed, expected) {
+		t.Fatalf("expected close order %v but got %v", expected, closed)
+	}
+
+	closeErr, ok := err.(*CloseError)
+	if !ok || !reflect.DeepEqual(closeErr.Errors, []error{errConsumer, errTmp}) {
+		t.Fatalf("expected the aggregated close errors but got: %v", err)
+	}
+
+	if expected := "2 errors occurred while closing: consumer failed; tmp failed"; err.Error() != expected {
+		t.Fatalf("expected error message %q but got %q", expected, err.Error())
+	}
+
+	if m.Has(1) || !m.Has(2) {
+		t.Fatal("expected only the closed values to be removed")
+	}
+
+	if err = m.Close(); err != nil || len(closed) != 3 {
+		t.Fatalf("expected a second close to do nothing but got: %v, %v", err, closed)
+	}
+}
+
+func TestMemoryUntrack(t *testing.T) {
+	var closed []string
+
+	m := makeMemory()
+	m.Set(1, testCloser{name: "first", closed: &closed})
+	m.Set(2, testCloser{name: "second", closed: &closed})
+	for i := 0; i < 10; i++ {
+		m.Unset(1)
+		m.Set(1, testCloser{name: "first", closed: &closed}) // re-insertion is the last one.
+	}
+
+	if len(m.order) != 2 {
+		t.Fatalf("expected the removed values to be untracked but got %d tracked", len(m.order))
+	}
+
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"first", "second"}; !reflect.DeepEqual(closed, expected) {
+		t.Fatalf("expected close order %v but got %v", expected, closed)
+	}
+}
+
+func TestApplicationCloseMemoryOnInterrupt(t *testing.T) {
+	var (
+		closed      []string
+		interrupted = make(chan os.Signal, 1)
+	)
+
+	app := &Application{
+		Memory: makeMemory(),
+		OnInterrupt: func(sig os.Signal, closeErr error) {
+			if closeErr != nil {
+				t.Errorf("unexpected close error: %v", closeErr)
+			}
+			interrupted <- sig
+		},
+	}
+	app.Memory.Set(1, testCloser{name: "http", closed: &closed})
+
+	// stopped without an interrupt.
+	app.handleInterrupt(make(chan os.Signal))()
+	if len(closed) != 0 || len(interrupted) != 0 {
+		t.Fatalf("expected the memory to not be closed without an interrupt but got %v", closed)
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	stop := app.handleInterrupt(interrupt)
+	interrupt <- os.Interrupt
+	if sig := <-interrupted; sig != os.Interrupt {
+		t.Fatalf("expected the OnInterrupt to be called with the interrupt signal but got: %v", sig)
+	}
+	stop()
+
+	if err := app.closeMemory(&cobra.Command{}, nil); err != nil || !reflect.DeepEqual(closed, []string{"http"}) {
+		t.Fatalf("expected the memory to be closed once but got %v (%v)", closed, err)
+	}
+
+	if runtime.GOOS == "windows" {
+		return // interrupt signals can not be sent to the process itself.
+	}
+
+	app.Memory.Set(1, testCloser{name: "consumer", closed: &closed})
+	stop = app.closeMemoryOnInterrupt()
+	defer stop()
+
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = p.Signal(os.Interrupt); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-interrupted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the OnInterrupt to be called on the process interrupt")
+	}
+
+	if expected := []string{"http", "consumer"}; !reflect.DeepEqual(closed, expected) {
+		t.Fatalf("expected the memory to be closed on the process interrupt but got %v", closed)
+	}
+}
@@ -0,0 +1,71 @@
+package bite
+
+import "fmt"
+
+// Key is a typed `Memory` key, the type of its value is checked at compile time, i.e:
+//
+//	const ClientKey bite.Key[*http.Client] = 1
+//	ClientKey.Set(bite.GetMemory(cmd), client)
+//	client, ok := ClientKey.Get(bite.GetMemory(cmd))
+//
+// It shares the uint8 key space with the untyped `Memory` functions.
+type Key[T any] uint8
+
+// Set stores the "value", reports whether an existing value was replaced, safe for concurrent access.
+func (k Key[T]) Set(m *Memory, value T) bool {
+	return m.Set(uint8(k), value)
+}
+
+// Get returns the stored value and true, or the zero value and false if missing or not a T, safe for concurrent access.
+func (k Key[T]) Get(m *Memory) (T, bool) {
+	var zero T
+
+	v, ok := m.Get(uint8(k))
+	if !ok {
+		return zero, false
+	}
+
+	value, ok := v.(T)
+	if !ok {
+		return zero, false
+	}
+
+	return value, true
+}
+
+// MustGet is like `Get` but it panics if the value is missing or it's not a T.
+func (k Key[T]) MustGet(m *Memory) T {
+	v, ok := m.Get(uint8(k))
+	if !ok {
+		panic(fmt.Sprintf("mem: key for %d missing", k))
+	}
+
+	value, ok := v.(T)
+	if !ok {
+		panic(fmt.Sprintf("mem: value for key %d is %T, not %T", k, v, value))
+	}
+
+	return value
+}
+
+// SetOnceFunc stores the value of the "receiverFunc" if the key is not there already,
+// the "receiverFunc" is not called at all otherwise. Its error, if any, is returned and the value is not stored.
+// Concurrent calls of the same key call a single "receiverFunc", the rest wait for it and they get its error, if any.
+func (k Key[T]) SetOnceFunc(m *Memory, receiverFunc func() (T, error)) error {
+	return m.setOnceFunc(uint8(k), func() (interface{}, error) {
+		value, err := receiverFunc()
+		return value, err
+	})
+}
+
+// Visit calls the "visitorFunc" with the stored value, if found and it's a T, reports whether it was called.
+// Like `Memory.Visit` it doesn't lock while the function runs, so it can access the memory.
+func (k Key[T]) Visit(m *Memory, visitorFunc func(T)) bool {
+	value, ok := k.Get(m)
+	if !ok {
+		return false
+	}
+
+	visitorFunc(value)
+	return true
+}
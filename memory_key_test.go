@@ -0,0 +1,88 @@
+package bite
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestKey(t *testing.T) {
+	const (
+		nameKey Key[string] = 1
+		portKey Key[int]    = 2
+	)
+
+	m := makeMemory()
+
+	if _, ok := nameKey.Get(m); ok {
+		t.Fatal("expected a missing value")
+	}
+
+	nameKey.Set(m, "bite")
+	if name, ok := nameKey.Get(m); !ok || name != "bite" {
+		t.Fatalf("expected the stored value but got: %q", name)
+	}
+
+	// same uint8 key, different type.
+	if _, ok := Key[int](nameKey).Get(m); ok {
+		t.Fatal("expected a value of a different type to be missing")
+	}
+
+	errFailed := errors.New("failed")
+	if err := portKey.SetOnceFunc(m, func() (int, error) { return 0, errFailed }); err != errFailed {
+		t.Fatalf("expected the func's error but got: %v", err)
+	}
+
+	if m.Has(uint8(portKey)) {
+		t.Fatal("expected the value of a failed func to not be stored")
+	}
+
+	portKey.SetOnceFunc(m, func() (int, error) { return 9092, nil })
+	portKey.SetOnceFunc(m, func() (int, error) { return 0, nil })
+	if port := portKey.MustGet(m); port != 9092 {
+		t.Fatalf("expected the first value but got: %d", port)
+	}
+}
+
+func TestKeySetOnceFuncConcurrent(t *testing.T) {
+	const clientKey Key[*testClient] = 1
+
+	m := makeMemory()
+
+	var (
+		calls int32
+		wg    sync.WaitGroup
+	)
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			clientKey.SetOnceFunc(m, func() (*testClient, error) {
+				atomic.AddInt32(&calls, 1)
+				time.Sleep(5 * time.Millisecond)
+				return &testClient{name: "kafka"}, nil
+			})
+		}()
+	}
+	wg.Wait()
+
+	if calls := atomic.LoadInt32(&calls); calls != 1 {
+		t.Fatalf("expected the func to be called once but called %d times", calls)
+	}
+
+	var visited string
+	if !clientKey.Visit(m, func(c *testClient) { visited = c.name }) || visited != "kafka" {
+		t.Fatalf("expected the stored value to be visited but got: %q", visited)
+	}
+
+	if Key[string](clientKey).Visit(m, func(string) { t.Fatal("unexpected visit of a different type") }) {
+		t.Fatal("expected a value of a different type to not be visited")
+	}
+}
+
+type testClient struct {
+	name string
+}
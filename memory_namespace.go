@@ -0,0 +1,200 @@
+package bite
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// MemoryNamespace is a view of the `Memory` values of a namespace, see `Memory.Namespace`.
+// Its keys can be of any comparable type, i.e strings, so independent libraries do not collide on small uint8 keys.
+type MemoryNamespace struct {
+	m    *Memory
+	name string
+}
+
+// Namespace returns the "name" namespace of the memory, i.e `mem.Namespace("kafka").Set("client", c)`.
+// Namespaced values are kept apart from the uint8 keyed ones.
+func (m *Memory) Namespace(name string) *MemoryNamespace {
+	return &MemoryNamespace{m: m, name: name}
+}
+
+// Namespaces returns the sorted names of the namespaces that have at least one value, safe for concurrent access.
+func (m *Memory) Namespaces() []string {
+	m.mu.RLock()
+	names := make([]string, 0, len(m.namespaces))
+	for name, values := range m.namespaces {
+		if len(values) > 0 {
+			names = append(names, name)
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.Strings(names)
+	return names
+}
+
+// Name returns the name of the namespace.
+func (ns *MemoryNamespace) Name() string {
+	return ns.name
+}
+
+// isComparableKey reports whether the "key" can be used as a map key, i.e slices and maps can not.
+func isComparableKey(key interface{}) bool {
+	return key != nil && reflect.TypeOf(key).Comparable()
+}
+
+func (ns *MemoryNamespace) checkKey(key interface{}) {
+	if !isComparableKey(key) {
+		panic(fmt.Sprintf("mem: key %v of namespace %s is not comparable", key, ns.name))
+	}
+}
+
+// Set stores the "value" under the "key", reports whether an existing value was replaced, safe for concurrent access.
+func (ns *MemoryNamespace) Set(key, value interface{}) (replacement bool) {
+	ns.checkKey(key)
+	m := ns.m
+	if m.namespaces == nil {
+		return
+	}
+
+	m.mu.Lock()
+	values, ok := m.namespaces[ns.name]
+	if !ok {
+		values = make(map[interface{}]interface{})
+		m.namespaces[ns.name] = values
+	}
+
+	_, replacement = values[key]
+	values[key] = value
+	m.track(memoryRef{namespace: ns.name, namespaced: true, key: key})
+	m.mu.Unlock()
+
+	return
+}
+
+// SetOnce stores the "value" under the "key" if it's not there already, safe for concurrent access.
+func (ns *MemoryNamespace) SetOnce(key, value interface{}) bool {
+	ns.checkKey(key)
+	m := ns.m
+	if m.namespaces == nil {
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	values, ok := m.namespaces[ns.name]
+	if !ok {
+		values = make(map[interface{}]interface{})
+		m.namespaces[ns.name] = values
+	}
+
+	if _, exists := values[key]; exists {
+		return false
+	}
+
+	values[key] = value
+	m.track(memoryRef{namespace: ns.name, namespaced: true, key: key})
+	return true
+}
+
+// Unset removes the value of the "key", safe for concurrent access.
+// It reports false if the "key" is not comparable.
+func (ns *MemoryNamespace) Unset(key interface{}) (removed bool) {
+	if !isComparableKey(key) {
+		return
+	}
+
+	m := ns.m
+	m.mu.Lock()
+	if values := m.namespaces[ns.name]; values != nil {
+		if _, removed = values[key]; removed {
+			m.untrack(memoryRef{namespace: ns.name, namespaced: true, key: key})
+			delete(values, key)
+		}
+	}
+	m.mu.Unlock()
+
+	return
+}
+
+// Has reports whether the "key" exists, safe for concurrent access.
+// It reports false if the "key" is not comparable.
+func (ns *MemoryNamespace) Has(key interface{}) bool {
+	_, found := ns.Get(key)
+	return found
+}
+
+// Get returns the value of the "key", safe for concurrent access.
+// It reports false if the "key" is not comparable.
+func (ns *MemoryNamespace) Get(key interface{}) (value interface{}, found bool) {
+	if !isComparableKey(key) {
+		return
+	}
+
+	m := ns.m
+	m.mu.RLock()
+	value, found = m.namespaces[ns.name][key]
+	m.mu.RUnlock()
+
+	return
+}
+
+// MustGet is like `Get` but it panics if the value is missing or nil.
+func (ns *MemoryNamespace) MustGet(key interface{}) interface{} {
+	v, ok := ns.Get(key)
+	if !ok {
+		panic(fmt.Sprintf("mem: key for %v missing in namespace %s", key, ns.name))
+	}
+
+	if v == nil {
+		panic(fmt.Sprintf("mem: value for key %v is nil in namespace %s", key, ns.name))
+	}
+
+	return v
+}
+
+// Keys returns the keys of the namespace, sorted by their string form, safe for concurrent access.
+func (ns *MemoryNamespace) Keys() []interface{} {
+	m := ns.m
+	m.mu.RLock()
+	keys := make([]interface{}, 0, len(m.namespaces[ns.name]))
+	for key := range m.namespaces[ns.name] {
+		keys = append(keys, key)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+	})
+
+	return keys
+}
+
+// GetAll returns a clone of all the values of the namespace, safe for concurrent access.
+func (ns *MemoryNamespace) GetAll() map[interface{}]interface{} {
+	m := ns.m
+	m.mu.RLock()
+	clone := make(map[interface{}]interface{}, len(m.namespaces[ns.name]))
+	for key, value := range m.namespaces[ns.name] {
+		clone[key] = value
+	}
+	m.mu.RUnlock()
+
+	return clone
+}
+
+// Clear removes all the values of the namespace and returns their length, safe for concurrent access.
+func (ns *MemoryNamespace) Clear() int {
+	m := ns.m
+	m.mu.Lock()
+	n := len(m.namespaces[ns.name])
+	for key := range m.namespaces[ns.name] {
+		m.untrack(memoryRef{namespace: ns.name, namespaced: true, key: key})
+	}
+	delete(m.namespaces, ns.name)
+	m.mu.Unlock()
+
+	return n
+}
@@ -0,0 +1,68 @@
+package bite
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMemoryNamespace(t *testing.T) {
+	m := makeMemory()
+	m.Set(1, "uint8")
+
+	kafka := m.Namespace("kafka")
+	kafka.Set("client", "kafka client")
+	kafka.Set("brokers", 3)
+	m.Namespace("http").Set(1, "http client")
+
+	if v, ok := kafka.Get("client"); !ok || v != "kafka client" {
+		t.Fatalf("expected the namespaced value but got: %v", v)
+	}
+
+	if v := m.Namespace("http").MustGet(1); v != "http client" {
+		t.Fatalf("expected the namespaced value of an int key but got: %v", v)
+	}
+
+	if v := m.MustGet(1); v != "uint8" {
+		t.Fatalf("expected the uint8 key to not collide but got: %v", v)
+	}
+
+	if expected := []interface{}{"brokers", "client"}; !reflect.DeepEqual(kafka.Keys(), expected) {
+		t.Fatalf("expected keys %v but got %v", expected, kafka.Keys())
+	}
+
+	if expected := []string{"http", "kafka"}; !reflect.DeepEqual(m.Namespaces(), expected) {
+		t.Fatalf("expected namespaces %v but got %v", expected, m.Namespaces())
+	}
+
+	if n := m.Clear(); n != 4 {
+		t.Fatalf("expected 4 removed values but got %d", n)
+	}
+
+	if kafka.Has("client") {
+		t.Fatal("expected the namespaced values to be cleared")
+	}
+}
+
+func TestMemoryNamespaceKeyNotComparable(t *testing.T) {
+	ns := makeMemory().Namespace("kafka")
+	ns.Set("client", "kafka client")
+
+	keys := []interface{}{nil, []string{"client"}, map[string]int{"client": 1}, func() {}}
+	for _, key := range keys {
+		if v, ok := ns.Get(key); ok || v != nil {
+			t.Fatalf("expected no value for key %#v but got: %v", key, v)
+		}
+
+		if ns.Has(key) {
+			t.Fatalf("expected key %#v to not exist", key)
+		}
+
+		if ns.Unset(key) {
+			t.Fatalf("expected key %#v to not be removed", key)
+		}
+	}
+
+	if !ns.Has("client") {
+		t.Fatal("expected the comparable key to be kept")
+	}
+}
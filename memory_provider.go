@@ -0,0 +1,133 @@
+package bite
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// providersNamespace is the memory namespace of the resolved provider values.
+const providersNamespace = "bite.providers"
+
+// Provider constructs a value, its dependencies are resolved through the "r", see `Memory.Provide`.
+type Provider func(r *Resolver) (interface{}, error)
+
+// Resolver resolves the dependencies of a provider and detects cycles, see `Memory.Provide`.
+type Resolver struct {
+	m    *Memory
+	path []string
+}
+
+// ProviderError is the error of a provider, or of a missing or cyclic one, while resolving another one.
+type ProviderError struct {
+	// Provider is the name of the provider that failed.
+	Provider string
+	// Path is the resolution path, the first is the requested provider and the last is the failed one.
+	Path []string
+	Err  error
+}
+
+func (e *ProviderError) Error() string {
+	if len(e.Path) < 2 {
+		// provider "credentials" failed: no token
+		return fmt.Sprintf("provider %s failed: %v", strconv.Quote(e.Provider), e.Err)
+	}
+
+	// provider "credentials" failed while resolving "http-client" (http-client -> config -> credentials): no token
+	return fmt.Sprintf("provider %s failed while resolving %s (%s): %v",
+		strconv.Quote(e.Provider), strconv.Quote(e.Path[0]), strings.Join(e.Path, " -> "), e.Err)
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// providerError returns the "err" if it's, or wraps, a `ProviderError` of a dependency,
+// otherwise a new `ProviderError` of the "name" provider.
+func providerError(name string, path []string, err error) error {
+	for wrapped := err; wrapped != nil; {
+		if _, ok := wrapped.(*ProviderError); ok {
+			return wrapped
+		}
+
+		unwrapper, ok := wrapped.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		wrapped = unwrapper.Unwrap()
+	}
+
+	return &ProviderError{Provider: name, Path: path, Err: err}
+}
+
+// Provide registers a named provider, i.e `mem.Provide("http-client", func(r *bite.Resolver) (interface{}, error) { ... })`.
+// Providers are resolved lazily, once, on the first `Resolve` and their dependencies are resolved through the `Resolver.Resolve`,
+// i.e an HTTP client that depends on the config which depends on the credentials. Registering a provider again replaces it
+// and resets its resolved value. The resolved values are kept until the memory is closed, i.e after each command's execution,
+// see `Close`, so the next execution resolves them again.
+func (m *Memory) Provide(name string, provider Provider) {
+	m.mu.Lock()
+	if m.providers == nil {
+		m.providers = make(map[string]Provider)
+	}
+	m.providers[name] = provider
+	m.mu.Unlock()
+
+	m.Namespace(providersNamespace).Unset(name)
+}
+
+// Resolve returns the value of the "name" provider, it's constructed on the first call, safe for concurrent access.
+// Failures are returned as `ProviderError` and they are not kept, so the next call tries again.
+func (m *Memory) Resolve(name string) (interface{}, error) {
+	return (&Resolver{m: m}).Resolve(name)
+}
+
+// MustResolve is like `Resolve` but it panics on failure.
+func (m *Memory) MustResolve(name string) interface{} {
+	v, err := m.Resolve(name)
+	if err != nil {
+		panic(fmt.Sprintf("mem: %v", err))
+	}
+
+	return v
+}
+
+// Resolve returns the value of the "name" provider, it fails if the provider depends on itself.
+func (r *Resolver) Resolve(name string) (interface{}, error) {
+	path := append(append([]string(nil), r.path...), name)
+
+	for _, resolving := range r.path {
+		if resolving == name {
+			return nil, &ProviderError{Provider: name, Path: path, Err: fmt.Errorf("dependency cycle")}
+		}
+	}
+
+	values := r.m.Namespace(providersNamespace)
+	if v, ok := values.Get(name); ok {
+		return v, nil
+	}
+
+	r.m.mu.RLock()
+	provider, ok := r.m.providers[name]
+	r.m.mu.RUnlock()
+
+	if !ok {
+		return nil, &ProviderError{Provider: name, Path: path, Err: fmt.Errorf("not registered")}
+	}
+
+	v, err := provider(&Resolver{m: r.m, path: path})
+	if err != nil {
+		return nil, providerError(name, path, err)
+	}
+
+	// another goroutine may have resolved it meanwhile, keep the first one and close ours, it's not used by anyone.
+	if !values.SetOnce(name, v) {
+		if closeFunc := closer(v); closeFunc != nil {
+			closeFunc()
+		}
+
+		v, _ = values.Get(name)
+	}
+
+	return v, nil
+}
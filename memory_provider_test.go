@@ -0,0 +1,107 @@
+package bite
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestMemoryProvide(t *testing.T) {
+	m := makeMemory()
+
+	calls := 0
+	m.Provide("credentials", func(*Resolver) (interface{}, error) {
+		calls++
+		return "token", nil
+	})
+	m.Provide("config", func(r *Resolver) (interface{}, error) {
+		credentials, err := r.Resolve("credentials")
+		if err != nil {
+			return nil, err
+		}
+
+		return fmt.Sprintf("config(%s)", credentials), nil
+	})
+	m.Provide("http-client", func(r *Resolver) (interface{}, error) {
+		config, err := r.Resolve("config")
+		if err != nil {
+			return nil, err
+		}
+
+		return fmt.Sprintf("client(%s)", config), nil
+	})
+
+	for i := 0; i < 2; i++ {
+		if v := m.MustResolve("http-client"); v != "client(config(token))" {
+			t.Fatalf("unexpected value: %v", v)
+		}
+	}
+
+	if calls != 1 {
+		t.Fatalf("expected the provider to be called once but called %d times", calls)
+	}
+
+	errNoToken := errors.New("no token")
+	m.Provide("credentials", func(*Resolver) (interface{}, error) { return nil, errNoToken })
+	m.Namespace(providersNamespace).Clear()
+
+	_, err := m.Resolve("http-client")
+	expected := `provider "credentials" failed while resolving "http-client" (http-client -> config -> credentials): no token`
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error:\n%s\nbut got:\n%v", expected, err)
+	}
+
+	m.Provide("credentials", func(r *Resolver) (interface{}, error) { return r.Resolve("http-client") })
+	_, err = m.Resolve("http-client")
+	expected = `provider "http-client" failed while resolving "http-client" (http-client -> config -> credentials -> http-client): dependency cycle`
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error:\n%s\nbut got:\n%v", expected, err)
+	}
+}
+
+func TestMemoryProvideClose(t *testing.T) {
+	var closed []string
+
+	m := makeMemory()
+	calls := 0
+	m.Provide("http-client", func(*Resolver) (interface{}, error) {
+		calls++
+		return testCloser{name: "http-client", closed: &closed}, nil
+	})
+
+	m.MustResolve("http-client")
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// next execution.
+	m.MustResolve("http-client")
+	if calls != 2 || len(closed) != 1 {
+		t.Fatalf("expected the provider to be resolved again after close but called %d times and closed %v", calls, closed)
+	}
+
+	m.Provide("config", func(*Resolver) (interface{}, error) { return "config", nil })
+	m.MustResolve("config")
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := m.Namespace(providersNamespace).Get("config"); ok {
+		t.Fatal("expected the not closable resolved values to be removed on close")
+	}
+
+	// another goroutine resolves it while ours is constructed.
+	winner := testCloser{name: "winner", closed: &closed}
+	m.Provide("consumer", func(*Resolver) (interface{}, error) {
+		m.Namespace(providersNamespace).Set("consumer", winner)
+		return testCloser{name: "loser", closed: &closed}, nil
+	})
+
+	if v := m.MustResolve("consumer"); v != winner {
+		t.Fatalf("expected the first resolved value but got: %v", v)
+	}
+
+	if expected := []string{"http-client", "http-client", "loser"}; fmt.Sprint(closed) != fmt.Sprint(expected) {
+		t.Fatalf("expected closed %v but got %v", expected, closed)
+	}
+}
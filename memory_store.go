@@ -0,0 +1,401 @@
+package bite
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// DefaultCacheDir returns the default directory of the persistent memory of an application, i.e `~/.cache/mycli`.
+// It fails if the user's cache directory is unknown, i.e the `$HOME` is not set.
+func DefaultCacheDir(applicationName string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, applicationName), nil
+}
+
+// DiskStore keeps `Memory` values on disk between invocations, one file per key, see `Memory.Persist`.
+// Writes are atomic and they are guarded by a lock file, so concurrent runs of the CLI do not corrupt the store.
+type DiskStore struct {
+	Dir string
+	// Gob if true(default is false) then the values are encoded with gob instead of JSON.
+	Gob bool
+	// LockTimeout is the maximum time to wait for the lock of another run, defaults to 5 seconds.
+	LockTimeout time.Duration
+}
+
+// NewDiskStore returns a new `DiskStore` which keeps its files to the "dir" directory.
+func NewDiskStore(dir string) *DiskStore {
+	return &DiskStore{Dir: dir, LockTimeout: 5 * time.Second}
+}
+
+const (
+	diskStoreLockFilename = ".lock"
+	// a lock older than that is left by a killed run.
+	diskStoreStaleLock = time.Minute
+)
+
+func (s *DiskStore) path(key uint8) string {
+	ext := ".json"
+	if s.Gob {
+		ext = ".gob"
+	}
+
+	return filepath.Join(s.Dir, strconv.Itoa(int(key))+ext)
+}
+
+// isDiskStoreFile reports whether the "name" is the file name of a stored value, in any encoding, see `DiskStore.path`.
+func isDiskStoreFile(name string) bool {
+	ext := filepath.Ext(name)
+	if ext != ".json" && ext != ".gob" {
+		return false
+	}
+
+	key := strings.TrimSuffix(name, ext)
+	n, err := strconv.ParseUint(key, 10, 8)
+	return err == nil && strconv.FormatUint(n, 10) == key
+}
+
+func (s *DiskStore) lock() (unlock func(), err error) {
+	if err = os.MkdirAll(s.Dir, 0700); err != nil {
+		return nil, err
+	}
+
+	lockPath := filepath.Join(s.Dir, diskStoreLockFilename)
+	deadline := time.Now().Add(s.LockTimeout)
+
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > diskStoreStaleLock {
+			breakStaleLock(lockPath, info)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("mem: store '%s' is locked by another run", s.Dir)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// breakStaleLock removes the stale lock that the "info" describes, the lock is acquired again through the exclusive create.
+// The lock is renamed to a unique name first, so only one of the waiting runs removes it, and if it's not the stale one,
+// i.e another run broke and re-created it meanwhile, it's put back.
+func breakStaleLock(lockPath string, info os.FileInfo) {
+	stale := fmt.Sprintf("%s.stale-%d-%d", lockPath, os.Getpid(), time.Now().UnixNano())
+	if err := os.Rename(lockPath, stale); err != nil {
+		return // broken by another run.
+	}
+
+	if renamed, err := os.Stat(stale); err == nil && !os.SameFile(info, renamed) {
+		os.Link(stale, lockPath) // fails if a newer lock exists, it's the valid one then.
+	}
+
+	os.Remove(stale)
+}
+
+type diskStoreEntry struct {
+	ExpiresAt time.Time       `json:"expiresAt"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// Write stores the "value" of the "key", a zero "expiresAt" means never.
+func (s *DiskStore) Write(key uint8, value interface{}, expiresAt time.Time) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return s.write(key, value, expiresAt)
+}
+
+func (s *DiskStore) write(key uint8, value interface{}, expiresAt time.Time) error {
+	buf := new(bytes.Buffer)
+
+	if s.Gob {
+		enc := gob.NewEncoder(buf)
+		if err := enc.Encode(expiresAt); err != nil {
+			return err
+		}
+
+		if err := enc.Encode(value); err != nil {
+			return err
+		}
+	} else {
+		b, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		if err = json.NewEncoder(buf).Encode(diskStoreEntry{ExpiresAt: expiresAt, Value: b}); err != nil {
+			return err
+		}
+	}
+
+	// write to a temp file and rename, so readers never see a partial file.
+	f, err := ioutil.TempFile(s.Dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+
+	if _, err = f.Write(buf.Bytes()); err == nil {
+		err = f.Close()
+	} else {
+		f.Close()
+	}
+
+	if err == nil {
+		err = os.Rename(f.Name(), s.path(key))
+	}
+
+	if err != nil {
+		os.Remove(f.Name())
+	}
+
+	return err
+}
+
+// Read decodes the stored value of the "key" to the "outPtr" and returns its expiration time, a zero one means never.
+func (s *DiskStore) Read(key uint8, outPtr interface{}) (expiresAt time.Time, found bool, err error) {
+	b, err := ioutil.ReadFile(s.path(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+
+		return
+	}
+
+	if s.Gob {
+		dec := gob.NewDecoder(bytes.NewReader(b))
+		if err = dec.Decode(&expiresAt); err == nil {
+			err = dec.Decode(outPtr)
+		}
+	} else {
+		var entry diskStoreEntry
+		if err = json.Unmarshal(b, &entry); err == nil {
+			expiresAt = entry.ExpiresAt
+			err = json.Unmarshal(entry.Value, outPtr)
+		}
+	}
+
+	if err != nil {
+		return expiresAt, false, fmt.Errorf("mem: decode stored value of key %d: %v", key, err)
+	}
+
+	return expiresAt, true, nil
+}
+
+// Remove removes the stored value of the "key", if any.
+func (s *DiskStore) Remove(key uint8) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err = os.Remove(s.path(key)); os.IsNotExist(err) {
+		return nil
+	}
+
+	return err
+}
+
+// Clear removes all the stored values and returns their length.
+// Only the files of the stored values, i.e `1.json`, are removed, the `Dir` may be shared with other programs.
+func (s *DiskStore) Clear() (int, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	files, err := ioutil.ReadDir(s.Dir)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, file := range files {
+		if file.IsDir() || !isDiskStoreFile(file.Name()) {
+			continue
+		}
+
+		if err = os.Remove(filepath.Join(s.Dir, file.Name())); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	return n, nil
+}
+
+type persistedKey struct {
+	typ reflect.Type
+	ttl time.Duration
+}
+
+// Persist makes the value of the "key" to be kept on disk between invocations, for "ttl" (zero means never expires), see `Memory.Store`.
+// The "sample" is a value of the stored type, i.e `ClusterInfo{}`, the stored value is decoded to that type, it can not be nil.
+// The stored value, if not expired, is loaded immediately, so it should be called after a `SetLoader` of the same key, if any.
+func (m *Memory) Persist(key uint8, sample interface{}, ttl time.Duration) error {
+	if m.tmp == nil {
+		return nil
+	}
+
+	if sample == nil {
+		return fmt.Errorf("mem: sample of key %d is nil, a value of the stored type is required", key)
+	}
+
+	typ := reflect.TypeOf(sample)
+
+	m.mu.Lock()
+	if m.persisted == nil {
+		m.persisted = make(map[uint8]persistedKey)
+	}
+	m.persisted[key] = persistedKey{typ: typ, ttl: ttl}
+	m.mu.Unlock()
+
+	if m.Store == nil {
+		return m.storeErr // the value is still kept in memory for this run.
+	}
+
+	ptr := reflect.New(typ)
+	expiresAt, found, err := m.Store.Read(key, ptr.Interface())
+	if err != nil || !found {
+		return err
+	}
+
+	if !expiresAt.IsZero() && !m.now().Before(expiresAt) {
+		return nil // expired, it will be replaced on `Flush`.
+	}
+
+	m.mu.Lock()
+	m.tmp[key] = ptr.Elem().Interface()
+	m.track(memoryRef{key: key})
+	if expiresAt.IsZero() {
+		delete(m.expires, key)
+	} else {
+		m.expires[key] = expiresAt
+	}
+	m.mu.Unlock()
+
+	return nil
+}
+
+// Flush writes the current values of the persisted keys to the `Store`, see `Persist`.
+// It's called after the `Application.Shutdown` when the `Application.PersistentMemory` is true.
+func (m *Memory) Flush() error {
+	if m.Store == nil {
+		return nil
+	}
+
+	type entry struct {
+		key       uint8
+		value     interface{}
+		expiresAt time.Time
+	}
+
+	var entries []entry
+
+	m.mu.RLock()
+	for key, p := range m.persisted {
+		value, ok := m.tmp[key]
+		if !ok || m.expired(key) {
+			continue
+		}
+
+		expiresAt, ok := m.expires[key]
+		if !ok && p.ttl > 0 {
+			expiresAt = m.now().Add(p.ttl)
+		}
+
+		entries = append(entries, entry{key: key, value: value, expiresAt: expiresAt})
+	}
+	m.mu.RUnlock()
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	unlock, err := m.Store.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for _, e := range entries {
+		if err = m.Store.write(e.key, e.value, e.expiresAt); err != nil {
+			return fmt.Errorf("mem: store value of key %d: %v", e.key, err)
+		}
+	}
+
+	return nil
+}
+
+// ClearPersisted removes the values of the persisted keys from the memory and all the stored values from the `Store`,
+// it returns the length of the stored values that were removed.
+func (m *Memory) ClearPersisted() (int, error) {
+	m.mu.Lock()
+	for key := range m.persisted {
+		m.untrack(memoryRef{key: key})
+		delete(m.tmp, key)
+		delete(m.expires, key)
+	}
+	m.mu.Unlock()
+
+	if m.Store == nil {
+		return 0, m.storeErr
+	}
+
+	return m.Store.Clear()
+}
+
+func newCacheCommand(app *Application) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the cached values of " + app.Name,
+	}
+
+	clearCmd := &cobra.Command{
+		Use:           "clear",
+		Short:         "Remove all the cached values",
+		Example:       "cache clear",
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			n, err := app.Memory.ClearPersisted()
+			if err != nil {
+				return err
+			}
+
+			return PrintInfo(cmd, "%d cached values removed", n)
+		},
+	}
+
+	cmd.AddCommand(CanBeSilent(clearCmd))
+	return cmd
+}
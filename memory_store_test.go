@@ -0,0 +1,235 @@
+package bite
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+type testClusterInfo struct {
+	Brokers []string
+	Version string
+}
+
+func TestMemoryPersist(t *testing.T) {
+	for _, useGob := range []bool{false, true} {
+		dir := t.TempDir()
+
+		clock := &testClock{now: time.Date(2018, 5, 1, 0, 0, 0, 0, time.UTC)}
+		info := testClusterInfo{Brokers: []string{"b1", "b2"}, Version: "2.0"}
+
+		m := makeMemory()
+		m.Clock = clock
+		m.Store = NewDiskStore(dir)
+		m.Store.Gob = useGob
+
+		if err := m.Persist(1, testClusterInfo{}, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		m.Set(1, info)
+		m.Set(2, "not persisted")
+		if err := m.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		// next invocation.
+		next := makeMemory()
+		next.Clock = clock
+		next.Store = m.Store
+		if err := next.Persist(1, testClusterInfo{}, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, ok := next.Get(1); !ok || !reflect.DeepEqual(got, info) {
+			t.Fatalf("[gob=%v] expected the stored value but got: %#v", useGob, got)
+		}
+
+		if next.Has(2) {
+			t.Fatalf("[gob=%v] expected a not persisted key to be missing", useGob)
+		}
+
+		clock.now = clock.now.Add(time.Hour)
+		if next.Has(1) {
+			t.Fatalf("[gob=%v] expected the stored value to expire", useGob)
+		}
+
+		if n, err := next.ClearPersisted(); err != nil || n != 1 {
+			t.Fatalf("[gob=%v] expected 1 removed value but got %d (%v)", useGob, n, err)
+		}
+	}
+}
+
+func TestMemoryPersistNilSample(t *testing.T) {
+	if err := makeMemory().Persist(1, nil, 0); err == nil {
+		t.Fatal("expected an error for a nil sample")
+	}
+}
+
+func TestDiskStoreLock(t *testing.T) {
+	dir := t.TempDir()
+
+	store := NewDiskStore(dir)
+	store.LockTimeout = 50 * time.Millisecond
+
+	lockPath := filepath.Join(dir, diskStoreLockFilename)
+	if err := ioutil.WriteFile(lockPath, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Write(1, "value", time.Time{}); err == nil || !strings.Contains(err.Error(), "is locked by another run") {
+		t.Fatalf("expected a lock timeout but got: %v", err)
+	}
+
+	// left by a killed run.
+	stale := time.Now().Add(-2 * diskStoreStaleLock)
+	if err := os.Chtimes(lockPath, stale, stale); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Write(1, "value", time.Time{}); err != nil {
+		t.Fatalf("expected the stale lock to be broken but got: %v", err)
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 || files[0].Name() != "1.json" {
+		t.Fatalf("expected the value file only but got %d files", len(files))
+	}
+}
+
+func TestDiskStoreClear(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"1.json":       "{}",
+		"2.gob":        "",
+		"notes.json":   "{}",
+		"007.json":     "{}",
+		"300.json":     "{}",
+		"history.db":   "",
+		".config":      "",
+		"other/1.json": "{}",
+	})
+
+	n, err := NewDiskStore(dir).Clear()
+	if err != nil || n != 2 {
+		t.Fatalf("expected 2 removed values but got %d (%v)", n, err)
+	}
+
+	var kept []string
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			rel, _ := filepath.Rel(dir, path)
+			kept = append(kept, filepath.ToSlash(rel))
+		}
+		return err
+	})
+
+	if expected := []string{".config", "007.json", "300.json", "history.db", "notes.json", "other/1.json"}; !reflect.DeepEqual(kept, expected) {
+		t.Fatalf("expected the files of other programs to be kept but got %v", kept)
+	}
+}
+
+func TestApplicationUnknownCacheDir(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the cache directory is resolved by the environment on linux only")
+	}
+
+	t.Setenv("XDG_CACHE_HOME", "")
+	t.Setenv("HOME", "")
+
+	tests := []struct {
+		name        string
+		args        []string
+		expectedErr string
+	}{
+		{"not persisted", []string{"run"}, ""},
+		{"help", []string{"help"}, ""},
+		{"persisted", []string{"run", "--persist"}, "persistent memory: "},
+		{"cache clear", []string{"cache", "clear"}, "persistent memory: "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var persist bool
+
+			cmd := &cobra.Command{Use: "run", RunE: func(cmd *cobra.Command, _ []string) error {
+				mem := GetMemory(cmd)
+				if persist {
+					if err := mem.Persist(1, "", 0); err != nil {
+						return err
+					}
+				}
+
+				mem.Set(1, "value")
+				return nil
+			}}
+			cmd.Flags().BoolVar(&persist, "persist", false, "")
+
+			app := &Application{PersistentMemory: true}
+			app.AddCommand(cmd)
+
+			err := executeTestApp(t, app, tt.args...)
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			if err == nil || !strings.HasPrefix(err.Error(), tt.expectedErr) {
+				t.Fatalf("expected error %q but got: %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestApplicationFlushAfterShutdownFailure(t *testing.T) {
+	app := &Application{
+		PersistentMemory: true,
+		CacheDir:         t.TempDir(),
+		Shutdown: func(*cobra.Command, []string) error {
+			return fmt.Errorf("shutdown failed")
+		},
+	}
+	app.AddCommand(&cobra.Command{Use: "run", RunE: func(cmd *cobra.Command, _ []string) error {
+		mem := GetMemory(cmd)
+		if err := mem.Persist(1, "", 0); err != nil {
+			return err
+		}
+
+		mem.Set(1, "value")
+		return nil
+	}})
+
+	if err := executeTestApp(t, app, "run"); err == nil || err.Error() != "shutdown failed" {
+		t.Fatalf("expected the shutdown error but got: %v", err)
+	}
+
+	var value string
+	if _, found, err := app.Memory.Store.Read(1, &value); err != nil || !found || value != "value" {
+		t.Fatalf("expected the value to be flushed but got %q (%v)", value, err)
+	}
+}
+
+func TestDefaultCacheDir(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the cache directory is resolved by the environment on linux only")
+	}
+
+	t.Setenv("XDG_CACHE_HOME", "/tmp/cache")
+	if dir, err := DefaultCacheDir("mycli"); err != nil || dir != "/tmp/cache/mycli" {
+		t.Fatalf("expected the cache directory of the application but got %q (%v)", dir, err)
+	}
+
+	t.Setenv("XDG_CACHE_HOME", "")
+	t.Setenv("HOME", "")
+	if dir, err := DefaultCacheDir("mycli"); err == nil {
+		t.Fatalf("expected an error for an unknown cache directory but got %q", dir)
+	}
+}
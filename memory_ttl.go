@@ -0,0 +1,170 @@
+package bite
+
+import (
+	"fmt"
+	"time"
+)
+
+// Clock returns the current time, see `Memory.Clock`.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+type memoryLoader struct {
+	fn  func() (interface{}, error)
+	ttl time.Duration
+}
+
+func (m *Memory) now() time.Time {
+	if m.Clock == nil {
+		return systemClock{}.Now()
+	}
+
+	return m.Clock.Now()
+}
+
+// expired reports whether the value of the "key" is expired, the caller should hold the lock.
+func (m *Memory) expired(key uint8) bool {
+	expiresAt, ok := m.expires[key]
+	return ok && !m.now().Before(expiresAt)
+}
+
+// SetWithTTL same as `Set` but the value expires after the "ttl", i.e auth tokens, safe for concurrent access.
+// Expired values are ignored by `Has`, `Get` and `GetAll` and they are removed on access.
+func (m *Memory) SetWithTTL(key uint8, value interface{}, ttl time.Duration) (replacement bool) {
+	if m.tmp == nil {
+		return
+	}
+
+	replacement = m.Has(key)
+
+	m.mu.Lock()
+	m.tmp[key] = value
+	m.track(memoryRef{key: key})
+	m.expires[key] = m.now().Add(ttl)
+	delete(m.loaders, key)
+	m.mu.Unlock()
+
+	return
+}
+
+// SetLoader registers a lazy "loader" of the key's value, i.e cluster metadata, safe for concurrent access.
+// The value is loaded on the first `Get` or `Load` and it's loaded again after it expires, a zero "ttl" means never.
+// Concurrent calls of the same key wait for a single load. Failures are not stored, so the next call tries again.
+func (m *Memory) SetLoader(key uint8, ttl time.Duration, loader func() (interface{}, error)) {
+	if m.tmp == nil {
+		return
+	}
+
+	m.mu.Lock()
+	delete(m.tmp, key)
+	delete(m.expires, key)
+	m.loaders[key] = memoryLoader{fn: loader, ttl: ttl}
+	m.mu.Unlock()
+}
+
+// Load same as `Get` but it returns the loader's error, see `SetLoader`, or an error if the value is missing.
+func (m *Memory) Load(key uint8) (interface{}, error) {
+	value, found, err := m.load(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, fmt.Errorf("mem: key for %d missing", key)
+	}
+
+	return value, nil
+}
+
+// memoryLoad is an in-flight call of a loader, concurrent `Get`s of the same key wait for it instead of calling the loader again.
+type memoryLoad struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// evict removes the value of the "key" if it's expired, the caller should hold the lock.
+func (m *Memory) evict(key uint8) {
+	if _, ok := m.tmp[key]; ok && m.expired(key) {
+		m.untrack(memoryRef{key: key})
+		delete(m.tmp, key)
+		delete(m.expires, key)
+	}
+}
+
+func (m *Memory) load(key uint8) (interface{}, bool, error) {
+	m.mu.RLock()
+	value, found := m.tmp[key]
+	found = found && !m.expired(key)
+	m.mu.RUnlock()
+
+	if found {
+		return value, true, nil
+	}
+
+	m.mu.Lock()
+	m.evict(key)
+	if value, found = m.tmp[key]; found { // loaded meanwhile.
+		m.mu.Unlock()
+		return value, true, nil
+	}
+
+	loader, lazy := m.loaders[key]
+	if !lazy {
+		m.mu.Unlock()
+		return nil, false, nil
+	}
+
+	value, err := m.loadOnce(key, loader.fn, func(value interface{}) {
+		// the value is kept unless the loader was removed meanwhile, i.e by a `Set` or an `Unset`.
+		if _, lazy := m.loaders[key]; lazy {
+			m.tmp[key] = value
+			m.track(memoryRef{key: key})
+			if loader.ttl > 0 {
+				m.expires[key] = m.now().Add(loader.ttl)
+			}
+		}
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return value, true, nil
+}
+
+// loadOnce calls the "fn" of the "key" and passes its value to the "store", under lock, unless it fails.
+// Concurrent calls of the same key wait for the first one and they get its result.
+// The caller should hold the lock, it's released before the "fn" is called, as it may access the memory.
+func (m *Memory) loadOnce(key uint8, fn func() (interface{}, error), store func(value interface{})) (interface{}, error) {
+	if call, loading := m.loading[key]; loading {
+		m.mu.Unlock()
+		<-call.done
+		return call.value, call.err
+	}
+
+	call := &memoryLoad{done: make(chan struct{})}
+	if m.loading == nil {
+		m.loading = make(map[uint8]*memoryLoad)
+	}
+	m.loading[key] = call
+	m.mu.Unlock()
+
+	call.value, call.err = fn()
+
+	m.mu.Lock()
+	delete(m.loading, key)
+	if call.err == nil {
+		store(call.value)
+	}
+	m.mu.Unlock()
+	close(call.done)
+
+	return call.value, call.err
+}
@@ -0,0 +1,115 @@
+package bite
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func TestMemoryTTL(t *testing.T) {
+	clock := &testClock{now: time.Date(2018, 5, 1, 0, 0, 0, 0, time.UTC)}
+	m := makeMemory()
+	m.Clock = clock
+
+	m.SetWithTTL(1, "token", time.Minute)
+	if v, ok := m.Get(1); !ok || v != "token" {
+		t.Fatalf("expected the value before expiration but got: %v", v)
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	if m.Has(1) || len(m.GetAll()) != 0 {
+		t.Fatal("expected the value to be expired")
+	}
+
+	if v, ok := m.Get(1); ok || v != nil {
+		t.Fatalf("expected an expired value to not be found but got: %v", v)
+	}
+
+	loads := 0
+	errFailed := errors.New("failed")
+	m.SetLoader(2, time.Hour, func() (interface{}, error) {
+		if loads++; loads == 1 {
+			return nil, errFailed
+		}
+
+		return loads, nil
+	})
+
+	if _, err := m.Load(2); err != errFailed {
+		t.Fatalf("expected the loader's error but got: %v", err)
+	}
+
+	if v := m.MustGet(2); v != 2 {
+		t.Fatalf("expected the loaded value but got: %v", v)
+	}
+
+	clock.now = clock.now.Add(30 * time.Minute)
+	if v := m.MustGet(2); v != 2 {
+		t.Fatalf("expected the cached value but got: %v", v)
+	}
+
+	clock.now = clock.now.Add(30 * time.Minute)
+	if v := m.MustGet(2); v != 3 {
+		t.Fatalf("expected the value to be loaded again after expiration but got: %v", v)
+	}
+}
+
+func TestMemoryLoadOnce(t *testing.T) {
+	clock := &testClock{now: time.Date(2018, 5, 1, 0, 0, 0, 0, time.UTC)}
+	m := makeMemory()
+	m.Clock = clock
+
+	m.SetWithTTL(1, "token", time.Minute)
+	clock.now = clock.now.Add(time.Minute)
+	if m.Has(1) || len(m.tmp) != 0 || len(m.order) != 0 {
+		t.Fatal("expected the expired value to be removed on access")
+	}
+
+	var (
+		loads   int32
+		started = make(chan struct{})
+		release = make(chan struct{})
+	)
+
+	m.SetLoader(2, 0, func() (interface{}, error) {
+		if atomic.AddInt32(&loads, 1) == 1 {
+			close(started)
+		}
+		<-release
+		return "metadata", nil
+	})
+
+	var wg sync.WaitGroup
+	values := make([]interface{}, 10)
+	for i := range values {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			values[i], _ = m.Get(2)
+		}(i)
+	}
+
+	<-started
+	close(release)
+	wg.Wait()
+
+	if loads := atomic.LoadInt32(&loads); loads != 1 {
+		t.Fatalf("expected a single load but got %d", loads)
+	}
+
+	for i, v := range values {
+		if v != "metadata" {
+			t.Fatalf("expected the loaded value on call %d but got: %v", i, v)
+		}
+	}
+}
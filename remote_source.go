@@ -0,0 +1,190 @@
+package bite
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// RemoteSource describes how the remote contents of `@https://...` and `@file://...` flag values are fetched, see `TryReadFileContents`.
+//
+// A checksum can be pinned through the url's fragment, i.e `@https://artifacts.local/topic.yaml#sha256=<hex>`,
+// if the contents do not match then the read fails.
+// Plain `http://` urls are allowed only with a pinned checksum, unless `AllowInsecureHTTP` is set,
+// and `file://` urls are allowed only for local paths, i.e `file:///path` or `file://localhost/path`.
+type RemoteSource struct {
+	// Client is the http client that fetches the http(s) urls, defaults to `http.DefaultClient`.
+	Client *http.Client
+	// Timeout of each http(s) request, zero means no timeout (except the `Client`'s one).
+	Timeout time.Duration
+	// MaxSize is the maximum bytes that can be read from a source, zero means no limit.
+	MaxSize int64
+	// AllowInsecureHTTP allows plain `http://` urls without a pinned checksum.
+	AllowInsecureHTTP bool
+}
+
+// DefaultRemoteSource is the `RemoteSource` that `TryReadFileContents` uses, it can be modified by the application, i.e on its `Setup`.
+var DefaultRemoteSource = &RemoteSource{
+	Timeout: 30 * time.Second,
+	MaxSize: 10 << 20, // 10MB.
+}
+
+// IsRemoteSource reports whether the "source" (without the 'flagFilePrefix') is an url that `RemoteSource` can read.
+func IsRemoteSource(source string) bool {
+	return strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "file://")
+}
+
+// sourcePath returns the path part of a remote source, so its extension can be used to pick a decoder,
+// otherwise it returns the "source" as it's.
+func sourcePath(source string) string {
+	source = strings.TrimPrefix(source, string(flagFilePrefix))
+	if !IsRemoteSource(source) {
+		return source
+	}
+
+	u, err := url.Parse(source)
+	if err != nil {
+		return source
+	}
+
+	return u.Path
+}
+
+// Read returns the contents of the "source" url, decompressed if compressed.
+func (s *RemoteSource) Read(source string) ([]byte, error) {
+	u, err := url.Parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	checksum, err := parseChecksum(u.Fragment)
+	if err != nil {
+		return nil, err
+	}
+
+	var b []byte
+	switch u.Scheme {
+	case "file":
+		b, err = s.readFile(u)
+	case "http":
+		if checksum == nil && !s.AllowInsecureHTTP {
+			return nil, fmt.Errorf("insecure source '%s': use https or pin its checksum, i.e '#sha256=<hex>'", source)
+		}
+		b, err = s.fetch(u)
+	case "https":
+		b, err = s.fetch(u)
+	default:
+		err = fmt.Errorf("unsupported source scheme '%s'", u.Scheme)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if checksum != nil {
+		if got := sha256.Sum256(b); !bytes.Equal(got[:], checksum) {
+			return nil, fmt.Errorf("checksum mismatch for '%s': expected sha256 %x but got %x", source, checksum, got)
+		}
+	}
+
+	// the decompressed contents are limited too, so a small compressed source can not expand without limit.
+	return decompressWith(u.Path, b, func(r io.Reader) ([]byte, error) {
+		return s.readAll(source, r)
+	})
+}
+
+// parseChecksum parses the `sha256=<hex>` fragment of a source url, it returns nil if there is no fragment.
+func parseChecksum(fragment string) ([]byte, error) {
+	if fragment == "" {
+		return nil, nil
+	}
+
+	const prefix = "sha256="
+	if !strings.HasPrefix(fragment, prefix) {
+		return nil, fmt.Errorf("unsupported checksum '%s', expected %s<hex>", fragment, prefix)
+	}
+
+	checksum, err := hex.DecodeString(fragment[len(prefix):])
+	if err != nil || len(checksum) != sha256.Size {
+		return nil, fmt.Errorf("invalid sha256 checksum '%s'", fragment[len(prefix):])
+	}
+
+	return checksum, nil
+}
+
+func (s *RemoteSource) readFile(u *url.URL) ([]byte, error) {
+	if u.Host != "" && u.Host != "localhost" {
+		return nil, fmt.Errorf("unsupported file source '%s': host '%s' is not local, expected file:///path", u.String(), u.Host)
+	}
+
+	f, err := os.Open(u.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return s.readAll(u.String(), f)
+}
+
+func (s *RemoteSource) fetch(u *url.URL) ([]byte, error) {
+	ctx := context.Background()
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+
+	target := *u
+	target.Fragment = "" // the checksum is not part of the request.
+	req, err := http.NewRequest(http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("failed to fetch '%s': %s", req.URL, resp.Status)
+	}
+
+	if s.MaxSize > 0 && resp.ContentLength > s.MaxSize {
+		return nil, fmt.Errorf("'%s' exceeds the size limit of %d bytes", req.URL, s.MaxSize)
+	}
+
+	return s.readAll(req.URL.String(), resp.Body)
+}
+
+func (s *RemoteSource) readAll(source string, r io.Reader) ([]byte, error) {
+	if s.MaxSize <= 0 {
+		return ioutil.ReadAll(r)
+	}
+
+	b, err := ioutil.ReadAll(io.LimitReader(r, s.MaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(b)) > s.MaxSize {
+		return nil, fmt.Errorf("'%s' exceeds the size limit of %d bytes", source, s.MaxSize)
+	}
+
+	return b, nil
+}
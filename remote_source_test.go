@@ -0,0 +1,132 @@
+package bite
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTryReadFileRemote(t *testing.T) {
+	const contents = "name: topic\npartitions: 3\n"
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/topic.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+
+		fmt.Fprint(w, contents)
+	}))
+	defer srv.Close()
+
+	prevSource := DefaultRemoteSource
+	DefaultRemoteSource = &RemoteSource{Client: srv.Client(), MaxSize: 1024}
+	defer func() { DefaultRemoteSource = prevSource }()
+
+	var got struct {
+		Name       string `yaml:"name"`
+		Partitions int    `yaml:"partitions"`
+	}
+
+	checksum := sha256.Sum256([]byte(contents))
+	if err := TryReadFile(fmt.Sprintf("@%s/topic.yaml#sha256=%x", srv.URL, checksum), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Name != "topic" || got.Partitions != 3 {
+		t.Fatalf("expected name 'topic' and partitions 3 but got '%s' and %d", got.Name, got.Partitions)
+	}
+
+	tests := []struct {
+		source        string
+		expectedError string
+	}{
+		{fmt.Sprintf("@%s/topic.yaml#sha256=%x", srv.URL, sha256.Sum256([]byte("other"))), "checksum mismatch"},
+		{"@" + srv.URL + "/missing.yaml", "404 Not Found"},
+	}
+
+	for i, tt := range tests {
+		if _, err := TryReadFileContents(tt.source); err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+			t.Fatalf("[%d] expected error to contain '%s' but got: %v", i, tt.expectedError, err)
+		}
+	}
+
+	DefaultRemoteSource.MaxSize = 10
+	if _, err := TryReadFileContents("@" + srv.URL + "/topic.yaml"); err == nil || !strings.Contains(err.Error(), "size limit") {
+		t.Fatalf("expected size limit error but got: %v", err)
+	}
+}
+
+func TestRemoteSourceInsecure(t *testing.T) {
+	const contents = "name: topic\n"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, contents)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(writeTestFiles(t, map[string]string{"topic.yaml": contents}), "topic.yaml")
+
+	checksum := sha256.Sum256([]byte(contents))
+
+	tests := []struct {
+		source        string
+		allowInsecure bool
+		expectedError string
+	}{
+		{srv.URL + "/topic.yaml", false, "insecure source"},
+		{fmt.Sprintf("%s/topic.yaml#sha256=%x", srv.URL, checksum), false, ""},
+		{srv.URL + "/topic.yaml", true, ""},
+		{"file://" + path, false, ""},
+		{"file://localhost" + path, false, ""},
+		{"file://fileserver" + path, false, "host 'fileserver' is not local"},
+	}
+
+	for i, tt := range tests {
+		source := &RemoteSource{Client: srv.Client(), AllowInsecureHTTP: tt.allowInsecure}
+		b, err := source.Read(tt.source)
+		if tt.expectedError != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Fatalf("[%d] expected error to contain '%s' but got: %v", i, tt.expectedError, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("[%d] %v", i, err)
+		}
+
+		if string(b) != contents {
+			t.Fatalf("[%d] expected contents %q but got %q", i, contents, b)
+		}
+	}
+}
+
+func TestRemoteSourceDecompressedSize(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := gzip.NewWriter(buf)
+	w.Write(bytes.Repeat([]byte("# padding\n"), 10<<10)) // 100KB.
+	w.Close()
+
+	dir := writeTestFiles(t, map[string]string{"topic.yaml.gz": buf.String()})
+	source := "file://" + filepath.ToSlash(filepath.Join(dir, "topic.yaml.gz"))
+
+	if b, err := (&RemoteSource{MaxSize: 200 << 10}).Read(source); err != nil || len(b) != 100<<10 {
+		t.Fatalf("expected %d decompressed bytes but got %d: %v", 100<<10, len(b), err)
+	}
+
+	// the compressed contents are within the limit, the decompressed ones are not.
+	if buf.Len() > 1024 {
+		t.Fatalf("expected the compressed contents to be less than 1024 bytes but got %d", buf.Len())
+	}
+
+	if _, err := (&RemoteSource{MaxSize: 1024}).Read(source); err == nil || !strings.Contains(err.Error(), "size limit") {
+		t.Fatalf("expected size limit error but got: %v", err)
+	}
+}
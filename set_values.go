@@ -0,0 +1,328 @@
+package bite
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// setFlagKey is the repeatable `--set path=value` flag, the value is converted to the target's type.
+	setFlagKey = "set"
+	// setStringFlagKey is the repeatable `--set-string path=value` flag, the value is kept as string when the target has no type.
+	setStringFlagKey = "set-string"
+	// setJSONFlagKey is the repeatable `--set-json path=json` flag, the value is decoded as JSON to the target.
+	setJSONFlagKey = "set-json"
+)
+
+// CanSetValues registers the `--set`, `--set-string` and `--set-json` flags to the "cmd" command,
+// their path=value assignments are applied on top of the loaded file, see `FileLoader.SetValues`.
+func CanSetValues(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().StringArray(setFlagKey, nil, "Set a value, i.e --set config.retention.ms=1000 or --set tags[0]=a, can be repeated")
+
+	cmd.Flags().StringArray(setStringFlagKey, nil, "Set a string value, i.e --set-string version=1, can be repeated")
+	cmd.Flags().StringArray(setJSONFlagKey, nil, `Set a JSON value, i.e --set-json 'tags=["a","b"]', can be repeated`)
+	return cmd
+}
+
+// SetValuesBind makes the file loader to apply the `--set` flags on top of the loaded file, see `FileLoader.SetValues`.
+func SetValuesBind() func(*FileLoader) {
+	return func(fl *FileLoader) {
+		fl.SetValues()
+	}
+}
+
+// SetValues makes the file loader to apply the `--set`, `--set-string` and `--set-json` assignments, in that order,
+// on top of the loaded file and the flag overrides, see `ApplySetValues`, they are applied when no file is loaded as well.
+// The flags should be registered through `CanSetValues`.
+func (fl *FileLoader) SetValues() *FileLoader {
+	fl.setValues = true
+	return fl
+}
+
+func (fl *FileLoader) applySetValues(cmd *cobra.Command, outPtr interface{}) error {
+	if !fl.setValues {
+		return nil
+	}
+
+	return ApplySetValues(cmd, outPtr)
+}
+
+// ApplySetValues applies the `--set`, `--set-string` and `--set-json` path=value assignments of the "cmd" command, in that order, to the "outPtr".
+func ApplySetValues(cmd *cobra.Command, outPtr interface{}) error {
+	set, _ := cmd.Flags().GetStringArray(setFlagKey)
+	setString, _ := cmd.Flags().GetStringArray(setStringFlagKey)
+	setJSON, _ := cmd.Flags().GetStringArray(setJSONFlagKey)
+
+	for _, assignments := range []struct {
+		values []string
+		kind   SetKind
+	}{{set, SetValue}, {setString, SetString}, {setJSON, SetJSON}} {
+		for _, assignment := range assignments.values {
+			if err := SetPath(outPtr, assignment, assignments.kind); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// SetKind is the kind of a path=value assignment, see `SetPath`.
+type SetKind uint8
+
+const (
+	// SetValue converts the value to the target's type, untyped targets, i.e `map[string]interface{}`,
+	// get numbers, booleans and null converted to their types, the `--set` flag.
+	SetValue SetKind = iota
+	// SetString is like `SetValue` but untyped targets get the value as string, the `--set-string` flag.
+	SetString
+	// SetJSON decodes the value as JSON to the target, the `--set-json` flag.
+	SetJSON
+)
+
+type pathSegment struct {
+	key   string
+	index int // -1 for keys.
+}
+
+// parsePath parses a dotted path with optional list indexes, i.e `consumers[0].group`, dots can be escaped, i.e `labels.app\.kubernetes\.io/name`.
+func parsePath(path string) ([]pathSegment, error) {
+	var (
+		segments []pathSegment
+		key      strings.Builder
+	)
+
+	flush := func() error {
+		if key.Len() == 0 {
+			return fmt.Errorf("invalid path '%s', empty path segment", path)
+		}
+
+		segments = append(segments, pathSegment{key: key.String(), index: -1})
+		key.Reset()
+		return nil
+	}
+
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; c {
+		case '\\':
+			if i+1 < len(path) {
+				i++
+			}
+			key.WriteByte(path[i])
+		case '.':
+			if i > 0 && path[i-1] == ']' {
+				continue
+			}
+
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		case '[':
+			if key.Len() > 0 {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+			}
+
+			end := strings.IndexByte(path[i:], ']')
+			if end == -1 {
+				return nil, fmt.Errorf("invalid path '%s', missing ']'", path)
+			}
+
+			index, err := strconv.Atoi(path[i+1 : i+end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid path '%s', invalid index '%s'", path, path[i+1:i+end])
+			}
+
+			segments = append(segments, pathSegment{index: index})
+			i += end
+		default:
+			key.WriteByte(c)
+		}
+	}
+
+	if key.Len() > 0 || len(segments) == 0 {
+		if err := flush(); err != nil {
+			return nil, err
+		}
+	}
+
+	return segments, nil
+}
+
+// SetPath applies a `path=value` assignment to the "outPtr", which can be a pointer to any struct, map or slice,
+// i.e `config.retention.ms=1000` or `tags[0]=a`. Struct fields are matched by their flag, json, yaml tag or name,
+// maps, slices and pointers are created or grown when needed and the value is converted to the type of the target.
+// The rest of the path is the key of a map of non-object values, so `config.retention.ms` sets the "retention.ms" key of a `Config map[string]string` field.
+func SetPath(outPtr interface{}, assignment string, kind SetKind) error {
+	idx := strings.IndexByte(assignment, '=')
+	if idx <= 0 {
+		return fmt.Errorf("invalid value '%s', expected path=value", assignment)
+	}
+
+	path, value := assignment[:idx], assignment[idx+1:]
+	segments, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+
+	v := reflect.ValueOf(outPtr)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("set '%s': outPtr is not a pointer", path)
+	}
+
+	if err = setPathValue(v.Elem(), segments, value, kind); err != nil {
+		return fmt.Errorf("set '%s': %v", path, err)
+	}
+
+	return nil
+}
+
+func setPathValue(v reflect.Value, segments []pathSegment, value string, kind SetKind) error {
+	if len(segments) == 0 {
+		return setAssignmentValue(v, value, kind)
+	}
+
+	segment := segments[0]
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+
+		return setPathValue(v.Elem(), segments, value, kind)
+	case reflect.Interface:
+		var current reflect.Value
+		if !v.IsNil() {
+			current = v.Elem()
+		} else if segment.index >= 0 {
+			current = reflect.ValueOf([]interface{}{})
+		} else {
+			current = reflect.ValueOf(map[string]interface{}{})
+		}
+
+		// interface values are not addressable, work on a copy.
+		elem := reflect.New(current.Type()).Elem()
+		elem.Set(current)
+		if err := setPathValue(elem, segments, value, kind); err != nil {
+			return err
+		}
+
+		v.Set(elem)
+		return nil
+	case reflect.Struct:
+		if segment.index >= 0 {
+			return fmt.Errorf("index [%d] of a non-list value", segment.index)
+		}
+
+		name := normalizeFlagName(segment.key)
+		for i, n := 0, v.NumField(); i < n; i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath != "" { // unexported.
+				continue
+			}
+
+			if fieldFlagName(field) == name {
+				return setPathValue(v.Field(i), segments[1:], value, kind)
+			}
+		}
+
+		return fmt.Errorf("unknown field '%s'", segment.key)
+	case reflect.Map:
+		if segment.index >= 0 {
+			return fmt.Errorf("index [%d] of a non-list value", segment.index)
+		}
+
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+
+		keyStr, rest := segment.key, segments[1:]
+		if !isObjectType(v.Type().Elem()) {
+			// the rest of the path is the key, i.e `config.retention.ms`.
+			for len(rest) > 0 && rest[0].index == -1 {
+				keyStr += "." + rest[0].key
+				rest = rest[1:]
+			}
+		}
+
+		key := reflect.New(v.Type().Key()).Elem()
+		if err := setValueFromString(key, keyStr); err != nil {
+			return err
+		}
+
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if existing := v.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		}
+
+		if err := setPathValue(elem, rest, value, kind); err != nil {
+			return err
+		}
+
+		v.SetMapIndex(key, elem)
+		return nil
+	case reflect.Slice:
+		if segment.index < 0 {
+			return fmt.Errorf("key '%s' of a list value", segment.key)
+		}
+
+		if n := segment.index + 1; v.Len() < n {
+			grown := reflect.MakeSlice(v.Type(), n, n)
+			reflect.Copy(grown, v)
+			v.Set(grown)
+		}
+
+		return setPathValue(v.Index(segment.index), segments[1:], value, kind)
+	}
+
+	return fmt.Errorf("path of a %s value", v.Type())
+}
+
+// isObjectType reports whether the "typ" holds nested values that a path can walk through.
+func isObjectType(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		return typ != timeTyp
+	case reflect.Map, reflect.Slice, reflect.Interface:
+		return true
+	}
+
+	return false
+}
+
+func setAssignmentValue(v reflect.Value, value string, kind SetKind) error {
+	if kind == SetJSON {
+		ptr := reflect.New(v.Type())
+		if err := json.Unmarshal([]byte(value), ptr.Interface()); err != nil {
+			return err
+		}
+
+		v.Set(ptr.Elem())
+		return nil
+	}
+
+	if v.Kind() == reflect.Interface {
+		if kind == SetString {
+			v.Set(reflect.ValueOf(value))
+		} else if typed := parseSetValueType(value); typed != nil {
+			v.Set(reflect.ValueOf(typed))
+		} else {
+			v.Set(reflect.Zero(v.Type()))
+		}
+
+		return nil
+	}
+
+	return setValueFromString(v, value)
+}
@@ -0,0 +1,113 @@
+package bite
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+type testSetResource struct {
+	Name      string            `json:"name"`
+	Replicas  *int              `json:"replicas"`
+	Retention time.Duration     `json:"retention"`
+	Config    map[string]string `json:"config"`
+	Tags      []string          `json:"tags"`
+	Consumers []struct {
+		Group string `json:"group"`
+	} `json:"consumers"`
+	Extra map[string]interface{} `json:"extra"`
+}
+
+func TestSetPath(t *testing.T) {
+	var r testSetResource
+
+	assignments := []struct {
+		assignment string
+		kind       SetKind
+	}{
+		{"name=topic", SetValue},
+		{"replicas=3", SetValue},
+		{"retention=1h", SetValue},
+		{"config.retention.ms=1000", SetValue},
+		{"tags[1]=b", SetValue},
+		{"consumers[0].group=g1", SetValue},
+		{"extra.labels.version=1", SetString},
+		{"extra.limit=10", SetValue},
+		{`extra.owners=["a","b"]`, SetJSON},
+	}
+
+	for _, a := range assignments {
+		if err := SetPath(&r, a.assignment, a.kind); err != nil {
+			t.Fatalf("%s: %v", a.assignment, err)
+		}
+	}
+
+	if r.Name != "topic" || r.Replicas == nil || *r.Replicas != 3 || r.Retention != time.Hour {
+		t.Fatalf("unexpected scalar values: %#v", r)
+	}
+
+	if expected := map[string]string{"retention.ms": "1000"}; !reflect.DeepEqual(r.Config, expected) {
+		t.Fatalf("expected config %v but got %v", expected, r.Config)
+	}
+
+	if expected := []string{"", "b"}; !reflect.DeepEqual(r.Tags, expected) {
+		t.Fatalf("expected tags %q but got %q", expected, r.Tags)
+	}
+
+	if len(r.Consumers) != 1 || r.Consumers[0].Group != "g1" {
+		t.Fatalf("unexpected consumers: %#v", r.Consumers)
+	}
+
+	expectedExtra := map[string]interface{}{
+		"labels": map[string]interface{}{"version": "1"},
+		"limit":  int64(10),
+		"owners": []interface{}{"a", "b"},
+	}
+	if !reflect.DeepEqual(r.Extra, expectedExtra) {
+		t.Fatalf("expected extra %#v but got %#v", expectedExtra, r.Extra)
+	}
+
+	if err := SetPath(&r, "unknown=1", SetValue); err == nil || err.Error() != "set 'unknown': unknown field 'unknown'" {
+		t.Fatalf("expected unknown field error but got: %v", err)
+	}
+
+	if err := SetPath(&r, "replicas=x", SetValue); err == nil {
+		t.Fatal("expected a conversion error")
+	}
+}
+
+func TestFileBindSetValues(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{"topic.yaml": "name: {{ .Values.tenant }}-topic\ntags: [a]\n"})
+	path := filepath.Join(dir, "topic.yaml")
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected testSetResource
+	}{
+		{"no file", []string{"--set=name=topic", "--set-string=tags[0]=b"}, testSetResource{Name: "topic", Tags: []string{"b"}}},
+		// the --set-value values are consumed by the templates and the --set ones are applied on the loaded file.
+		{"template file", []string{"--file=" + path, "--set-value=tenant=acme", "--set=tags[1]=b"}, testSetResource{Name: "acme-topic", Tags: []string{"a", "b"}}},
+	}
+
+	for _, tt := range tests {
+		var r testSetResource
+
+		cmd := CanSetValues(CanTemplateFiles(CanLoadFiles(&cobra.Command{Use: "create"})))
+		cmd.RunE = FileBind(&r, TemplateBind(), SetValuesBind())
+		cmd.SetArgs(tt.args)
+		cmd.SetOutput(ioutil.Discard)
+
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("[%s] %v", tt.name, err)
+		}
+
+		if !reflect.DeepEqual(r, tt.expected) {
+			t.Fatalf("[%s] expected %#v but got %#v", tt.name, tt.expected, r)
+		}
+	}
+}